@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// versionAction is something that can be done with the highlighted version
+type versionAction int
+
+// Version actions, in menu order
+const (
+	actionDownload versionAction = iota
+	actionPreview
+	actionPull
+	actionCopyReference
+	actionDiff
+	actionSubchart
+	actionCustom
+)
+
+// menuAction is an action menu entry with its accelerator key
+type menuAction struct {
+	action versionAction
+	label  string
+	key    string
+}
+
+// menuActions lists the action menu entries
+var menuActions = []menuAction{
+	{actionDownload, "Download values", "enter"},
+	{actionPreview, "Preview values", "v"},
+	{actionPull, "Pull chart", "p"},
+	{actionCopyReference, "Copy reference", "y"},
+	{actionDiff, "Diff release", "d"},
+	{actionSubchart, "Subchart values", "c"},
+}
+
+// pullCompleteMsg reports the chart archive written by helm pull
+type pullCompleteMsg struct {
+	version HelmVersion
+	path    string
+}
+
+// clipboardMsg reports the outcome of a copy to the clipboard
+type clipboardMsg struct {
+	text string
+	err  error
+}
+
+// pullChart downloads the chart archive for a version into the current directory
+func pullChart(version HelmVersion) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := runHelm(appContext, "pull", "--version", version.Version, "--", version.Name); err != nil {
+			return errorMsg(fmt.Sprintf("Failed to pull chart: %v", err))
+		}
+
+		chartParts := strings.Split(version.Name, "/")
+		return pullCompleteMsg{version: version, path: fmt.Sprintf("%s-%s.tgz", chartParts[len(chartParts)-1], version.Version)}
+	}
+}
+
+// copyText copies text to the clipboard in the background
+func copyText(text string) tea.Cmd {
+	return func() tea.Msg {
+		return clipboardMsg{text: text, err: copyToClipboard(text)}
+	}
+}
+
+// chartReference returns the reference used to install a chart version with helm
+func chartReference(version HelmVersion) string {
+	return fmt.Sprintf("%s --version %s", version.Name, version.Version)
+}
+
+// runAction performs an action on the version under the cursor
+func (m model) runAction(action versionAction) (tea.Model, tea.Cmd) {
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+
+	m.menuOpen = false
+	if !m.actionAvailable(action) {
+		m.status = errorStyle.Render(missingPlugin(action))
+		return m, nil
+	}
+
+	m.selectedVersion = m.cursor
+	version := m.versions[m.selectedVersion]
+
+	if action != actionCopyReference && action != actionDiff && action != actionSubchart {
+		m = m.push()
+	}
+
+	switch action {
+	case actionDownload:
+		if m.opts.override {
+			m.loading = true
+			m.activity = "📖 Reading values to customise..."
+			m.state = stateDownload
+			return m, loadOverrideValues(version)
+		}
+		m.loading = true
+		m.activity = "⬇️  Downloading values.yaml..."
+		m.state = stateDownload
+		return m, downloadValuesProgress(m.repoFor(version.Name), version, m.opts)
+	case actionPreview:
+		m.loading = true
+		m.state = statePreview
+		return m, loadPreview(version.Name, version.Version)
+	case actionPull:
+		m.loading = true
+		m.activity = "📦 Pulling chart archive..."
+		m.state = stateDownload
+		return m, pullChart(version)
+	case actionCopyReference:
+		return m, copyText(chartReference(version))
+	case actionDiff:
+		return m.openInput(inputDiffRelease, "🔀 Release to diff against:"), nil
+	case actionSubchart:
+		label := "🧩 Subchart:"
+		if metadata := m.details[downloadKey(version.Name, version.Version)]; metadata != nil && len(metadata.Dependencies) > 0 {
+			names := make([]string, len(metadata.Dependencies))
+			for i, dep := range metadata.Dependencies {
+				names[i] = dep.Name
+			}
+			label = fmt.Sprintf("🧩 Subchart (%s):", strings.Join(names, ", "))
+		}
+		return m.openInput(inputSubchart, label), nil
+	}
+
+	return m, nil
+}
+
+// menuItems returns the menu entries whose required plugins are installed,
+// followed by the custom actions from the config file
+func (m model) menuItems() []menuAction {
+	var items []menuAction
+	for _, item := range menuActions {
+		if m.actionAvailable(item.action) {
+			items = append(items, item)
+		}
+	}
+	for _, action := range m.cfg.Actions {
+		items = append(items, menuAction{actionCustom, action.Name, action.Key})
+	}
+	return items
+}
+
+// runMenuItem performs the action of a menu entry; custom actions are
+// found by their key
+func (m model) runMenuItem(item menuAction) (tea.Model, tea.Cmd) {
+	if action, ok := m.customActionKey(item.key); ok && item.action == actionCustom {
+		return m.startCustomAction(action)
+	}
+	return m.runAction(item.action)
+}
+
+// updateMenu handles key presses while the action menu has focus
+func (m model) updateMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m.quit()
+	case "up", "k":
+		if m.menuCursor > 0 {
+			m.menuCursor--
+		}
+	case "down", "j":
+		if m.menuCursor < len(m.menuItems())-1 {
+			m.menuCursor++
+		}
+	case "enter", " ":
+		return m.runMenuItem(m.menuItems()[m.menuCursor])
+	case "tab", "shift+tab", "esc":
+		m.menuOpen = false
+	default:
+		for _, item := range m.menuItems() {
+			if msg.String() == item.key {
+				return m.runMenuItem(item)
+			}
+		}
+	}
+	return m, nil
+}
+
+// renderMenu draws the action menu
+func (m model) renderMenu() string {
+	var s strings.Builder
+	s.WriteString(selectedStyle.Render("⚡ Actions") + "\n")
+	for i, item := range m.menuItems() {
+		line := fmt.Sprintf("%-16s %s", item.label, helpStyle.UnsetMargins().Render("["+item.key+"]"))
+		if i == m.menuCursor {
+			s.WriteString(selectedStyle.Render("► ") + line)
+		} else {
+			s.WriteString("  " + line)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
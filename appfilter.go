@@ -0,0 +1,44 @@
+package main
+
+import "fmt"
+
+// filterByAppVersion keeps the versions whose app version is at least min
+// by semver. Versions whose app version is not semver cannot be compared;
+// they are left out and counted.
+func filterByAppVersion(versions []HelmVersion, min string) (kept []HelmVersion, nonSemver int) {
+	for _, v := range versions {
+		if !parseSemver(v.AppVersion).valid {
+			nonSemver++
+			continue
+		}
+		if compareVersions(v.AppVersion, min) >= 0 {
+			kept = append(kept, v)
+		}
+	}
+	return kept, nonSemver
+}
+
+// applyAppVersionFilter rebuilds the version list from allVersions with the
+// minimum app version, if any
+func (m model) applyAppVersionFilter() model {
+	m.rows = make(rowCache)
+	m.cursor = 0
+	m.nonSemverApps = 0
+	m.versions = m.allVersions
+	if m.minAppVersion != "" {
+		m.versions, m.nonSemverApps = filterByAppVersion(m.allVersions, m.minAppVersion)
+	}
+	return m
+}
+
+// appVersionFilterNote describes the active minimum app version filter
+func (m model) appVersionFilterNote() string {
+	if m.minAppVersion == "" {
+		return ""
+	}
+	note := fmt.Sprintf("🎯 App version ≥ %s: %d of %d versions", m.minAppVersion, len(m.versions), len(m.allVersions))
+	if m.nonSemverApps > 0 {
+		note += fmt.Sprintf(" • %d hidden, their app version is not semver", m.nonSemverApps)
+	}
+	return note
+}
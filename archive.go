@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archiveProgressMsg reports one version's values fetched for the archive
+type archiveProgressMsg struct {
+	ch <-chan tea.Msg
+}
+
+// archiveDoneMsg reports the outcome of archiving every version of a chart
+type archiveDoneMsg struct {
+	dir     string
+	paths   []string
+	written []HelmVersion
+	skipped []string
+	failed  []string
+}
+
+// archiveDir returns the directory the values of every version of a chart
+// are archived into, e.g. redis-values
+func archiveDir(repo HelmRepo, chartName string, opts options) string {
+	chartParts := strings.Split(chartName, "/")
+	return filepath.Join(downloadDir(repo, opts), chartParts[len(chartParts)-1]+"-values")
+}
+
+// archivePath returns the file a version's values are archived to, named
+// by the version
+func archivePath(dir, version string, opts options) string {
+	return filepath.Join(dir, valuesFilename(opts, version))
+}
+
+// archiveVersions downloads the default values of every version into dir,
+// fetching at most opts.concurrency at a time and reporting each one as it
+// arrives. With --archive-dedupe a version whose values are identical to the
+// next older version's is not written.
+func archiveVersions(repo HelmRepo, versions []HelmVersion, dir string, opts options) tea.Cmd {
+	ch := make(chan tea.Msg)
+
+	go func() {
+		values := make([][]byte, len(versions))
+		errs := make([]error, len(versions))
+		runPool(opts.concurrency, len(versions), func(i int) {
+			raw, err := fetchValues(versions[i].Name, versions[i].Version)
+			if err == nil {
+				err = checkValues(raw, opts)
+			}
+			if err == nil {
+				raw, err = transformValues(raw, opts)
+			}
+			values[i], errs[i] = raw, err
+			ch <- archiveProgressMsg{ch: ch}
+		})
+
+		done := archiveDoneMsg{dir: dir}
+		var previous []byte
+		// Oldest first, so each version is compared with the one before it
+		for i := len(versions) - 1; i >= 0; i-- {
+			version := versions[i]
+			if errs[i] != nil {
+				done.failed = append(done.failed, fmt.Sprintf("%s: %v", version.Version, errs[i]))
+				continue
+			}
+			if opts.archiveDedupe && previous != nil && bytes.Equal(values[i], previous) {
+				done.skipped = append(done.skipped, version.Version)
+				continue
+			}
+			previous = values[i]
+
+			path := archivePath(dir, version.Version, opts)
+			data := withSourceHeader(values[i], version.Name+" "+version.Version, repo, opts)
+			if err := writeValuesFile(path, data, opts); err != nil {
+				done.failed = append(done.failed, fmt.Sprintf("%s: %v", version.Version, err))
+				continue
+			}
+			done.paths = append(done.paths, path)
+			done.written = append(done.written, version)
+		}
+		ch <- done
+		close(ch)
+	}()
+
+	return waitForArchive(ch)
+}
+
+// waitForArchive waits for the next archive progress report or the result
+func waitForArchive(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// confirmArchive asks before archiving every version of the chart, since
+// it runs one helm command per version
+func (m model) confirmArchive() (tea.Model, tea.Cmd) {
+	m.menuOpen = false
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+	if !m.opts.shouldConfirm(promptArchive) {
+		return m.startArchive()
+	}
+	m.confirmingArchive = true
+	return m, nil
+}
+
+// updateConfirmArchive handles the answer to the archive confirmation
+func (m model) updateConfirmArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmingArchive = false
+	switch msg.String() {
+	case "y", "Y":
+		return m.startArchive()
+	case "ctrl+c":
+		return m.quit()
+	}
+	return m, nil
+}
+
+// startArchive starts downloading the values of every listed version
+func (m model) startArchive() (tea.Model, tea.Cmd) {
+	versions := m.versions
+	repo := m.repoFor(versions[0].Name)
+
+	m = m.push()
+	m.loading = true
+	m.archiveDone = 0
+	m.archiveTotal = len(versions)
+	m.activity = fmt.Sprintf("📦 Archiving values 0/%d...", len(versions))
+	m.state = stateDownload
+	return m, archiveVersions(repo, versions, archiveDir(repo, versions[0].Name, m.opts), m.opts)
+}
+
+// updateArchiveDone records the archived versions and shows the result
+func (m model) updateArchiveDone(msg archiveDoneMsg) (tea.Model, tea.Cmd) {
+	for i, version := range msg.written {
+		m.downloaded[downloadKey(version.Name, version.Version)] = true
+		m = m.record(stepValues, version, msg.paths[i], "")
+	}
+
+	m.loading = false
+	m.state = stateComplete
+	m.written = msg.paths
+	m.message = fmt.Sprintf("Archived the values of %d versions into %s", len(msg.paths), msg.dir)
+	if len(msg.skipped) > 0 {
+		m.message += fmt.Sprintf("\n   %d versions identical to the previous one were skipped: %s", len(msg.skipped), strings.Join(msg.skipped, ", "))
+	}
+	if len(msg.failed) > 0 {
+		m.message += "\n" + errorStyle.Render(fmt.Sprintf("❌ %d failed:\n   %s", len(msg.failed), strings.Join(msg.failed, "\n   ")))
+	}
+	return m, nil
+}
+
+// renderConfirmArchive draws the archive confirmation with the version count
+func (m model) renderConfirmArchive() string {
+	chartName := m.versions[0].Name
+	dir := archiveDir(m.repoFor(chartName), chartName, m.opts)
+
+	var s strings.Builder
+	s.WriteString(errorStyle.Render(fmt.Sprintf("📦 Download the default values of all %d listed versions of %s into %s?", len(m.versions), chartName, dir)) + "\n")
+	s.WriteString(fmt.Sprintf("   This runs %d helm commands, %d at a time.", len(m.versions), min(max(m.opts.concurrency, 1), len(m.versions))))
+	if m.opts.archiveDedupe {
+		s.WriteString(" Versions with the same values as the previous one are skipped.")
+	}
+	s.WriteString("\n\n")
+	s.WriteString(selectedStyle.Render("Continue? (y/N)"))
+	return s.String()
+}
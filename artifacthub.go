@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// artifactHubURL is the ArtifactHub repository search endpoint; tests point
+// it at a local server
+var artifactHubURL = "https://artifacthub.io/api/v1/repositories/search"
+
+// artifactHubClient queries ArtifactHub
+var artifactHubClient = &http.Client{Timeout: 15 * time.Second, Transport: newTransport(nil)}
+
+// artifactHubKindHelm is ArtifactHub's repository kind for Helm charts
+const artifactHubKindHelm = "0"
+
+// artifactHubLimit is how many repositories one search lists
+const artifactHubLimit = 50
+
+// hubRepo is a Helm repository found on ArtifactHub
+type hubRepo struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Organization string `json:"organization_name"`
+	User         string `json:"user_alias"`
+	Verified     bool   `json:"verified_publisher"`
+	Official     bool   `json:"official"`
+}
+
+// publisher returns who publishes the repository on ArtifactHub
+func (r hubRepo) publisher() string {
+	if r.Organization != "" {
+		return r.Organization
+	}
+	return r.User
+}
+
+// hubResultsMsg carries the repositories found on ArtifactHub, or why the
+// search failed
+type hubResultsMsg struct {
+	repos []hubRepo
+	err   error
+}
+
+// repoAddedMsg reports the outcome of helm repo add
+type repoAddedMsg struct {
+	name string
+	err  error
+}
+
+// searchArtifactHub looks up Helm repositories whose name matches term
+func searchArtifactHub(term string) tea.Cmd {
+	return func() tea.Msg {
+		repos, err := queryArtifactHub(term)
+		return hubResultsMsg{repos: repos, err: err}
+	}
+}
+
+// queryArtifactHub asks the ArtifactHub API for Helm repositories matching term
+func queryArtifactHub(term string) ([]hubRepo, error) {
+	query := url.Values{
+		"name":   {term},
+		"kind":   {artifactHubKindHelm},
+		"limit":  {fmt.Sprint(artifactHubLimit)},
+		"offset": {"0"},
+	}
+	req, err := http.NewRequestWithContext(appContext, http.MethodGet, artifactHubURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := artifactHubClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach ArtifactHub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ArtifactHub returned %s", resp.Status)
+	}
+
+	var repos []hubRepo
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("failed to parse the ArtifactHub response: %w", err)
+	}
+	return repos, nil
+}
+
+// addRepo adds a repository with helm repo add
+func addRepo(name, repoURL string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := runHelm(appContext, "repo", "add", "--", name, repoURL)
+		return repoAddedMsg{name: name, err: err}
+	}
+}
+
+// configuredRepo returns the name a repository URL is already configured
+// under, or "" when it is not
+func (m model) configuredRepo(repoURL string) string {
+	for _, repo := range m.allRepos {
+		if strings.TrimSuffix(repo.URL, "/") == strings.TrimSuffix(repoURL, "/") {
+			return repo.Name
+		}
+	}
+	return ""
+}
+
+// openHubRepo starts adding the highlighted ArtifactHub repository, asking
+// for the name to add it under
+func (m model) openHubRepo(index int) (tea.Model, tea.Cmd) {
+	m.cursor = index
+	repo := m.hubRepos[index]
+	switch {
+	case isOCIRef(repo.URL):
+		m.status = helpStyle.Render(fmt.Sprintf("OCI registries are not added with helm repo add; browse it with: helm-browser %s/<chart>", repo.URL))
+		return m, nil
+	case m.configuredRepo(repo.URL) != "":
+		m.status = helpStyle.Render(fmt.Sprintf("Already configured as %s", m.configuredRepo(repo.URL)))
+		return m, nil
+	}
+
+	m = m.openInput(inputAddRepo, fmt.Sprintf("➕ Add %s as:", repo.URL))
+	m.input.value = repo.Name
+	return m, nil
+}
+
+// submitAddRepo checks the name for the repository being added and runs
+// helm repo add
+func (m model) submitAddRepo(name string) (tea.Model, tea.Cmd) {
+	name = normalizeRepoName(name)
+	switch {
+	case name == "":
+		m.input.err = "enter a repository name"
+		return m, nil
+	case strings.ContainsAny(name, "/ "):
+		m.input.err = "repository names cannot contain / or spaces"
+		return m, nil
+	case repoIndex(m.allRepos, name) >= 0:
+		// helm would add Bitnami next to bitnami, which is only confusing
+		m.input.err = fmt.Sprintf("a repository named %s already exists", m.allRepos[repoIndex(m.allRepos, name)].Name)
+		return m, nil
+	}
+
+	m.input = inputPrompt{}
+	m.loading = true
+	m.activity = fmt.Sprintf("➕ Adding %s...", name)
+	return m, addRepo(name, m.hubRepos[m.cursor].URL)
+}
+
+// updateRepoAdded returns to the repository list and reloads it once a
+// repository was added; a failure keeps the search results open
+func (m model) updateRepoAdded(msg repoAddedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("❌ Failed to add repository: %v", msg.err))
+		return m, nil
+	}
+
+	m, _ = m.back()
+	m.loading = true
+	m.status = downloadedStyle.Render(fmt.Sprintf("✅ Added repository %s", msg.name))
+	return m, loadRepos()
+}
+
+// renderHubResults draws the ArtifactHub search results
+func (m model) renderHubResults() string {
+	var s strings.Builder
+	switch {
+	case m.loading:
+		if m.activity != "" {
+			s.WriteString(m.activity + "\n")
+		} else {
+			s.WriteString("🔄 Searching ArtifactHub...\n")
+		}
+		return s.String()
+	case m.hubErr != "":
+		s.WriteString(errorStyle.Render("❌ " + m.hubErr))
+		s.WriteString("\n\n")
+		s.WriteString(helpStyle.Render("Check your network connection and try again, or press Esc to go back."))
+		return s.String()
+	case len(m.hubRepos) == 0:
+		s.WriteString(fmt.Sprintf("📭 No Helm repositories on ArtifactHub match '%s'.\n", m.hubQuery))
+		return s.String()
+	}
+
+	s.WriteString("📦 Select a repository to add:\n\n")
+	s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-24s %-24s %s\n", "", "NAME", "PUBLISHER", "URL"), len(m.hubRepos)))
+	s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-24s %-24s %s\n", "────", strings.Repeat("─", 24), strings.Repeat("─", 24), strings.Repeat("─", 35)), len(m.hubRepos)))
+
+	start := m.getPageStart()
+	end := m.getPageEnd(len(m.hubRepos))
+	for i := start; i < end; i++ {
+		repo := m.hubRepos[i]
+		publisher := repo.publisher()
+		if repo.Official {
+			publisher = strings.TrimSpace(publisher + " ★")
+		} else if repo.Verified {
+			publisher = strings.TrimSpace(publisher + " ✓")
+		}
+
+		location := repo.URL
+		if name := m.configuredRepo(repo.URL); name != "" {
+			location += " (added as " + name + ")"
+		}
+
+		line := fmt.Sprintf("%-4s %s %s %s", m.rowLabel(i),
+			chartVersionStyle.Render(fmt.Sprintf("%-24s", shorten(repo.Name, 24))),
+			fmt.Sprintf("%-24s", shorten(publisher, 24)),
+			appVersionStyle.Render(location))
+		s.WriteString(m.gutter(i, len(m.hubRepos)))
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("► "+line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	info := fmt.Sprintf("📄 %d repositories found", len(m.hubRepos))
+	if rows := m.scrollInfo(len(m.hubRepos), "repositories found"); rows != "" {
+		info = rows
+	} else if totalPages := m.getTotalPages(); totalPages > 1 {
+		info = fmt.Sprintf("📄 Page %d of %d • %d repositories found", m.getCurrentPage()+1, totalPages, len(m.hubRepos))
+	}
+	s.WriteString(helpStyle.Render(info + " • ★ official • ✓ verified publisher"))
+	return s.String()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errNoBrowser means there is no display to open a browser on
+var errNoBrowser = errors.New("no browser available")
+
+// browserCommands open a URL with the default handler, per OS
+var browserCommands = map[string][]string{
+	"darwin":  {"open"},
+	"windows": {"rundll32", "url.dll,FileProtocolHandler"},
+	"linux":   {"xdg-open"},
+	"freebsd": {"xdg-open"},
+	"openbsd": {"xdg-open"},
+}
+
+// browserMsg reports the outcome of opening a URL in the browser
+type browserMsg struct {
+	url string
+	err error
+}
+
+// headless reports whether there is no graphical session to open a browser
+// in, e.g. over SSH or in a container
+func headless() bool {
+	display := os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	if os.Getenv("SSH_CONNECTION") != "" && !display {
+		return true
+	}
+	return runtime.GOOS != "darwin" && runtime.GOOS != "windows" && !display
+}
+
+// openBrowser opens target in the default browser
+func openBrowser(target string) error {
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%s is not a web address", target)
+	}
+
+	args, ok := browserCommands[runtime.GOOS]
+	if !ok || headless() {
+		return errNoBrowser
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return errNoBrowser
+	}
+	return exec.CommandContext(appContext, args[0], append(args[1:], target)...).Run()
+}
+
+// openURL opens a URL in the background
+func openURL(target string) tea.Cmd {
+	return func() tea.Msg {
+		return browserMsg{url: target, err: openBrowser(target)}
+	}
+}
+
+// openChartHome looks up the home URL of a chart version, or its first
+// source when it has no home, and opens it
+func openChartHome(chartName, version string) tea.Cmd {
+	return func() tea.Msg {
+		msg := loadDetails(chartName, version)().(detailsLoadedMsg)
+		if msg.metadata.err != nil {
+			return browserMsg{err: msg.metadata.err}
+		}
+		if msg.metadata.homePage() == "" {
+			return browserMsg{err: fmt.Errorf("%s has no home or source URL", chartName)}
+		}
+		return openURL(msg.metadata.homePage())()
+	}
+}
+
+// openHighlighted opens the highlighted repository's URL or chart's home page
+func (m model) openHighlighted() (tea.Model, tea.Cmd) {
+	switch {
+	case m.state == stateRepoList && m.cursor < len(m.repos) && m.repos[m.cursor].section == "":
+		return m, openURL(m.repos[m.cursor].URL)
+	case m.state == stateChartList && m.cursor < len(m.charts):
+		chart := m.charts[m.cursor]
+		return m, openChartHome(chart.Name, chart.Version)
+	case m.state == stateVersionList && m.cursor < len(m.versions):
+		version := m.versions[m.cursor]
+		if metadata := m.details[downloadKey(version.Name, version.Version)]; metadata != nil && metadata.homePage() != "" {
+			return m, openURL(metadata.homePage())
+		}
+		return m, openChartHome(version.Name, version.Version)
+	}
+	return m, nil
+}
+
+// browserStatus describes the outcome of opening a URL for the status line.
+// Without a browser the URL is shown so it can be opened by hand.
+func browserStatus(msg browserMsg) string {
+	switch {
+	case errors.Is(msg.err, errNoBrowser):
+		return appVersionStyle.Render("🌐 No browser available, open: " + hyperlink(msg.url, msg.url))
+	case msg.err != nil:
+		return errorStyle.Render(fmt.Sprintf("❌ Could not open browser: %v", msg.err))
+	}
+	return downloadedStyle.Render("🌐 Opened " + msg.url)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Chart sort orders
+const (
+	chartSortName     = "name"
+	chartSortVersions = "versions"
+)
+
+// versionCountsMsg carries the number of versions of each counted chart
+type versionCountsMsg map[string]int
+
+// countVersions looks up how many versions each chart has, running at most
+// limit helm commands at a time
+func countVersions(names []string, devel bool, limit int) tea.Cmd {
+	return func() tea.Msg {
+		counts := make([]int, len(names))
+		runPool(limit, len(names), func(i int) {
+			if versions, err := runVersions(names[i], devel); err == nil {
+				counts[i] = len(versions)
+			}
+		})
+
+		msg := make(versionCountsMsg, len(names))
+		for i, name := range names {
+			msg[name] = counts[i]
+		}
+		return msg
+	}
+}
+
+// sortCharts orders the chart list for the current sort mode, favorites
+// first when asked, and starts counting the versions of charts not counted
+// yet this session
+func (m model) sortCharts() (model, tea.Cmd) {
+	// Sort a copy: m.charts may be shared with the session cache
+	charts := append([]HelmChart(nil), m.charts...)
+
+	if m.chartSort != chartSortVersions {
+		sort.SliceStable(charts, func(i, j int) bool {
+			return strings.ToLower(charts[i].Name) < strings.ToLower(charts[j].Name)
+		})
+		m.charts = m.favoritesOnTop(charts)
+		return m, nil
+	}
+
+	var missing []string
+	for _, chart := range charts {
+		if _, ok := m.versionCounts[chart.Name]; !ok {
+			missing = append(missing, chart.Name)
+		}
+	}
+
+	// Charts with more versions first; charts still being counted last
+	sort.SliceStable(charts, func(i, j int) bool {
+		ci, oki := m.versionCounts[charts[i].Name]
+		cj, okj := m.versionCounts[charts[j].Name]
+		if oki != okj {
+			return oki
+		}
+		return ci > cj
+	})
+	m.charts = m.favoritesOnTop(charts)
+
+	if len(missing) == 0 || m.counting {
+		return m, nil
+	}
+	m.counting = true
+	return m, countVersions(missing, m.devel, m.opts.concurrency)
+}
+
+// favoritesOnTop moves the favorite charts to the top when favorites come
+// first, keeping the order within favorites and within the others
+func (m model) favoritesOnTop(charts []HelmChart) []HelmChart {
+	if m.favoritesFirst {
+		sort.SliceStable(charts, func(i, j int) bool {
+			return m.isFavorite(charts[i].Name) && !m.isFavorite(charts[j].Name)
+		})
+	}
+	return charts
+}
+
+// toggleChartSort switches between name and version-count order, keeping the
+// cursor on the highlighted chart
+func (m model) toggleChartSort() (model, tea.Cmd) {
+	if m.chartSort == chartSortVersions {
+		m.chartSort = chartSortName
+	} else {
+		m.chartSort = chartSortVersions
+	}
+	return m.resortCharts()
+}
+
+// resortCharts re-applies the chart order, keeping the cursor on the same chart
+func (m model) resortCharts() (model, tea.Cmd) {
+	current := ""
+	if m.cursor < len(m.charts) {
+		current = m.charts[m.cursor].Name
+	}
+
+	m, cmd := m.sortCharts()
+	for i, chart := range m.charts {
+		if chart.Name == current {
+			m.cursor = i
+			break
+		}
+	}
+	return m, cmd
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Chart types declared in Chart.yaml. Library charts only provide templates
+// to other charts and cannot be installed on their own.
+const (
+	chartTypeApplication = "application"
+	chartTypeLibrary     = "library"
+)
+
+// chartTypesMsg carries the type of each checked chart, empty when it could
+// not be read
+type chartTypesMsg map[string]string
+
+// loadChartTypes reads the type of each chart's latest version with helm
+// show chart, running at most limit helm commands at a time
+func loadChartTypes(names []string, limit int) tea.Cmd {
+	return func() tea.Msg {
+		types := make([]string, len(names))
+		runPool(limit, len(names), func(i int) {
+			output, err := runHelm(appContext, "show", "chart", "--", names[i])
+			if err != nil {
+				return
+			}
+			if metadata, err := parseChartMetadata(output); err == nil {
+				types[i] = metadata.Type
+			}
+		})
+
+		msg := make(chartTypesMsg, len(names))
+		for i, name := range names {
+			msg[name] = types[i]
+		}
+		return msg
+	}
+}
+
+// isLibrary reports whether a chart is known to be a library chart
+func (m model) isLibrary(chartName string) bool {
+	return m.chartTypes[chartName] == chartTypeLibrary
+}
+
+// withChartTypes leaves the known library charts out of the chart list when
+// they are hidden, keeping the cursor on the highlighted chart, and starts
+// reading the type of the charts not checked yet this session
+func (m model) withChartTypes() (model, tea.Cmd) {
+	if !m.hideLibrary {
+		return m, nil
+	}
+
+	current := ""
+	if m.cursor < len(m.charts) {
+		current = m.charts[m.cursor].Name
+	}
+
+	// Filter a copy: m.charts may be shared with the session cache
+	var charts []HelmChart
+	var missing []string
+	for _, chart := range m.charts {
+		if _, checked := m.chartTypes[chart.Name]; !checked {
+			missing = append(missing, chart.Name)
+		}
+		if !m.isLibrary(chart.Name) {
+			charts = append(charts, chart)
+		}
+	}
+	if len(charts) != len(m.charts) {
+		m.rows = make(rowCache)
+		m.charts = charts
+		m.cursor = min(m.cursor, max(len(charts)-1, 0))
+		for i, chart := range charts {
+			if chart.Name == current {
+				m.cursor = i
+				break
+			}
+		}
+	}
+
+	if len(missing) == 0 || m.checkingTypes {
+		return m, nil
+	}
+	m.checkingTypes = true
+	return m, loadChartTypes(missing, m.opts.concurrency)
+}
+
+// toggleLibraryCharts hides or shows the library charts in the chart list.
+// Shown again, the charts come back from the session cache, or from a new
+// search across all repositories.
+func (m model) toggleLibraryCharts() (tea.Model, tea.Cmd) {
+	m.hideLibrary = !m.hideLibrary
+	if m.hideLibrary {
+		return m.withChartTypes()
+	}
+
+	if m.searchQuery != "" {
+		return m.reloadList()
+	}
+	charts, ok := m.chartCache[m.repos[m.selectedRepo].Name]
+	if !ok {
+		return m.reloadList()
+	}
+	current := ""
+	if m.cursor < len(m.charts) {
+		current = m.charts[m.cursor].Name
+	}
+	m.rows = make(rowCache)
+	m.charts = charts
+	m.cursor = 0
+	for i, chart := range charts {
+		if chart.Name == current {
+			m.cursor = i
+			break
+		}
+	}
+	if m.chartSort == chartSortVersions || m.favoritesFirst {
+		return m.resortCharts()
+	}
+	return m, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// clipboardCommands are the native clipboard tools tried in order, per OS
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+// absolutePaths joins the absolute forms of paths, one per line, for copying
+func absolutePaths(paths []string) string {
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		abs[i] = path
+		if p, err := filepath.Abs(path); err == nil {
+			abs[i] = p
+		}
+	}
+	return strings.Join(abs, "\n")
+}
+
+// copyToClipboard puts text on the system clipboard. Native tools are used
+// when available; otherwise an OSC 52 sequence asks the terminal to do it,
+// which also works over SSH.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(appContext, args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// columnWidth is the widest an extra chart list column is drawn
+const columnWidth = 24
+
+// chartFields are the helm search repo fields HelmChart has fields for
+var chartFields = map[string]bool{"name": true, "version": true, "app_version": true, "description": true}
+
+// UnmarshalJSON reads a helm search repo entry, keeping fields HelmChart has
+// no field for in Extra so newer helm output can still be shown
+func (c *HelmChart) UnmarshalJSON(data []byte) error {
+	type plain HelmChart
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	c.Extra = nil
+	for key, raw := range fields {
+		if chartFields[key] {
+			continue
+		}
+		if c.Extra == nil {
+			c.Extra = make(map[string]json.RawMessage)
+		}
+		c.Extra[key] = raw
+	}
+	return nil
+}
+
+// MarshalJSON writes the chart with its extra fields, sorted by name, after
+// the known ones
+func (c HelmChart) MarshalJSON() ([]byte, error) {
+	type plain HelmChart
+	data, err := json.Marshal(plain(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(c.Extra))
+	for key := range c.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		buf.WriteString(",")
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(c.Extra[key])
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// chartColumn returns a field of a search result as text: strings as they
+// are, other JSON values compacted, and "" when helm did not report it
+func chartColumn(chart HelmChart, name string) string {
+	switch name {
+	case "name":
+		return chart.Name
+	case "version":
+		return chart.Version
+	case "app_version":
+		return chart.AppVersion
+	case "description":
+		return chart.Description
+	}
+
+	raw, ok := chart.Extra[name]
+	if !ok {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
+
+// columnCell pads or shortens a value to the extra column width
+func columnCell(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if utf8.RuneCountInString(value) > columnWidth {
+		value = string([]rune(value)[:columnWidth-1]) + "…"
+	}
+	return fmt.Sprintf("%-*s", columnWidth, value)
+}
+
+// columnHeaders returns the header and rule of the --columns columns
+func (m model) columnHeaders() (string, string) {
+	var header, rule strings.Builder
+	for _, name := range m.opts.columns {
+		header.WriteString(" " + columnCell(strings.ToUpper(strings.ReplaceAll(name, "_", " "))))
+		rule.WriteString(" " + strings.Repeat("─", columnWidth))
+	}
+	return header.String(), rule.String()
+}
+
+// columnValues renders the --columns cells of one chart
+func (m model) columnValues(chart HelmChart) string {
+	var s strings.Builder
+	for _, name := range m.opts.columns {
+		value := chartColumn(chart, name)
+		if value == "" {
+			value = "—"
+		}
+		s.WriteString(" " + columnCell(value))
+	}
+	return s.String()
+}
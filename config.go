@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// config holds the user preferences read from the configuration file
+type config struct {
+	// Groups maps repository names to the section they are listed under
+	Groups map[string]string `json:"groups,omitempty"`
+
+	// Order lists repositories in the order moved to with shift+up/down;
+	// repositories not listed follow in helm's order
+	Order []string `json:"order,omitempty"`
+
+	// ReloadKey reloads the current list without helm repo update, e.g.
+	// "ctrl+r" (the default) or "f5"
+	ReloadKey string `json:"reload_key,omitempty"`
+
+	// HideUnreachable checks the repositories at startup and leaves those
+	// that do not answer out of the repository list
+	HideUnreachable bool `json:"hide_unreachable,omitempty"`
+
+	// Favorites are the repositories and charts (by full name, e.g.
+	// "bitnami/redis") marked with * in the lists
+	Favorites []string `json:"favorites,omitempty"`
+
+	// FavoritesFirst starts with the favorites at the top of the lists
+	FavoritesFirst bool `json:"favorites_first,omitempty"`
+
+	// Pins maps chart names to the version downloaded instead of the latest
+	// one, e.g. {"bitnami/redis": "19.0.1"}
+	Pins map[string]string `json:"pins,omitempty"`
+
+	// Actions are custom commands run for the highlighted version from the
+	// version list or the action menu
+	Actions []customAction `json:"actions,omitempty"`
+}
+
+// defaultConfigPath returns the location of the configuration file when
+// --config is not given
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "helm-browser", "config.json")
+}
+
+// loadConfig reads the configuration file. A missing file yields an empty config.
+func loadConfig(path string) (config, error) {
+	var cfg config
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	reloadKey := cfg.ReloadKey
+	if reloadKey == "" {
+		reloadKey = defaultReloadKey
+	}
+	if err := validateActions(cfg.Actions, reloadKey); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the configuration file, creating its directory if needed
+func saveConfig(path string, cfg config) error {
+	if path == "" {
+		return fmt.Errorf("no configuration file path")
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// customAction is a command defined in the config file that is run for the
+// highlighted version, e.g.
+//
+//	{"name": "Lint", "key": "K", "command": "ct lint --charts {{.Name}}"}
+type customAction struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"`
+	Command string `json:"command"`
+}
+
+// customActionMsg carries what a custom action printed
+type customActionMsg struct {
+	action  customAction
+	version HelmVersion
+	output  []byte
+	err     error
+}
+
+// commandWords splits a command into words at spaces outside quotes. The
+// quotes themselves are removed, and a {{...}} template action is kept whole
+// so it may hold spaces and quotes of its own.
+func commandWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	for i := 0; i < len(command); {
+		if strings.HasPrefix(command[i:], "{{") {
+			end := strings.Index(command[i:], "}}")
+			if end < 0 {
+				// Left for the template parser to report
+				end = len(command) - i
+			} else {
+				end += len("}}")
+			}
+			word.WriteString(command[i : i+end])
+			inWord = true
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(command[i:])
+		i += size
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == '"' && r == '\\' && i < len(command) && (command[i] == '"' || command[i] == '\\'):
+			word.WriteByte(command[i])
+			i++
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// commandArgs splits the command into words and expands the template in
+// each one, so a value containing spaces or shell characters stays a single
+// argument and is never interpreted by a shell
+func (a customAction) commandArgs(data filenameData) ([]string, error) {
+	words, err := commandWords(a.Command)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, len(words))
+	for i, word := range words {
+		tmpl, err := template.New(a.Name).Parse(word)
+		if err != nil {
+			return nil, err
+		}
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, data); err != nil {
+			return nil, err
+		}
+		args[i] = arg.String()
+	}
+	return args, nil
+}
+
+// validateActions checks the custom actions from the config file: each one
+// needs a name, a free key and a command whose template only refers to
+// known fields
+func validateActions(actions []customAction, reloadKey string) error {
+	keys := map[string]bool{reloadKey: true}
+	for key := range listKeys {
+		keys[key] = true
+	}
+
+	for _, action := range actions {
+		switch {
+		case action.Name == "":
+			return fmt.Errorf("action with key %q has no name", action.Key)
+		case action.Key == "":
+			return fmt.Errorf("action %q has no key", action.Name)
+		case keys[action.Key]:
+			return fmt.Errorf("action %q: key %q is already used", action.Name, action.Key)
+		case strings.TrimSpace(action.Command) == "":
+			return fmt.Errorf("action %q has no command", action.Name)
+		}
+		keys[action.Key] = true
+
+		if _, err := action.commandArgs(filenameData{}); err != nil {
+			return fmt.Errorf("action %q: invalid command: %w", action.Name, err)
+		}
+	}
+	return nil
+}
+
+// runCustomAction runs a custom action's command with its arguments
+func runCustomAction(action customAction, version HelmVersion, args []string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := runCommand(appContext, args[0], args[1:]...)
+		return customActionMsg{action: action, version: version, output: output, err: err}
+	}
+}
+
+// customActionKey returns the custom action bound to key, if any
+func (m model) customActionKey(key string) (customAction, bool) {
+	for _, action := range m.cfg.Actions {
+		if action.Key == key {
+			return action, true
+		}
+	}
+	return customAction{}, false
+}
+
+// startCustomAction runs a custom action for the version under the cursor
+// and shows what it printed in the preview
+func (m model) startCustomAction(action customAction) (tea.Model, tea.Cmd) {
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+	m.menuOpen = false
+	m.selectedVersion = m.cursor
+	version := m.versions[m.selectedVersion]
+
+	args, err := action.commandArgs(templateData(m.repoFor(version.Name), version))
+	if err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("❌ %s: %v", action.Name, err))
+		return m, nil
+	}
+
+	m = m.push()
+	m.loading = true
+	m.activity = fmt.Sprintf("⚙️  Running %s...", action.Name)
+	m.state = stateDownload
+	return m, runCustomAction(action, version, args)
+}
+
+// updateCustomAction shows the output of a finished custom action, with the
+// error first when it failed
+func (m model) updateCustomAction(msg customActionMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	version := msg.version
+	output := msg.output
+	title := fmt.Sprintf("⚙️  %s for %s %s:", msg.action.Name, version.Name, version.Version)
+	if msg.err != nil {
+		title = errorStyle.Render(fmt.Sprintf("❌ %s failed: %v", msg.action.Name, msg.err))
+	}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		output = []byte("(no output)")
+	}
+	m.state = statePreview
+	m.preview = newViewport(output, m.viewportHeight())
+	m.preview.title = title
+	return m, nil
+}
+
+// customActionHelp returns the help line listing the custom actions
+func (m model) customActionHelp() string {
+	parts := make([]string, len(m.cfg.Actions))
+	for i, action := range m.cfg.Actions {
+		parts[i] = action.Name + ": " + keyLabel(action.Key)
+	}
+	return "🔧 " + strings.Join(parts, " • ")
+}
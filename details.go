@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// chartMetadata holds the Chart.yaml fields shown in the details panel
+type chartMetadata struct {
+	Name         string
+	Version      string
+	AppVersion   string
+	Description  string
+	Home         string
+	Sources      []string
+	Icon         string
+	KubeVersion  string
+	Type         string
+	Dependencies []chartDependency
+	Maintainers  []chartMaintainer
+	Annotations  []chartAnnotation
+
+	// Deprecated is set by deprecated: true or a deprecation annotation,
+	// whose text, if any, is kept in DeprecationNote
+	Deprecated      bool
+	DeprecationNote string
+
+	err error
+}
+
+// homePage returns the chart's home URL, falling back to its first source
+func (c *chartMetadata) homePage() string {
+	if c.Home == "" && len(c.Sources) > 0 {
+		return c.Sources[0]
+	}
+	return c.Home
+}
+
+// chartMaintainer is a maintainer listed in Chart.yaml
+type chartMaintainer struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+// chartAnnotation is an annotation of Chart.yaml, kept in document order
+type chartAnnotation struct {
+	Key   string
+	Value string
+}
+
+// annotationWidth is the longest annotation value shown before it is cut
+const annotationWidth = 80
+
+// chartDependency is a subchart listed in Chart.yaml
+type chartDependency struct {
+	Name    string
+	Version string
+}
+
+// detailsLoadedMsg carries the metadata of one chart version
+type detailsLoadedMsg struct {
+	key      string
+	metadata *chartMetadata
+}
+
+// loadDetails fetches the Chart.yaml of a chart version with helm show chart
+func loadDetails(chartName, version string) tea.Cmd {
+	return func() tea.Msg {
+		key := downloadKey(chartName, version)
+
+		output, err := runHelm(appContext, "show", "chart", "--version", version, "--", chartName)
+		if err != nil {
+			return detailsLoadedMsg{key: key, metadata: &chartMetadata{err: fmt.Errorf("failed to show chart: %w", err)}}
+		}
+
+		metadata, err := parseChartMetadata(output)
+		if err != nil {
+			return detailsLoadedMsg{key: key, metadata: &chartMetadata{err: fmt.Errorf("failed to parse chart: %w", err)}}
+		}
+		return detailsLoadedMsg{key: key, metadata: metadata}
+	}
+}
+
+// parseChartMetadata reads the fields of interest from a Chart.yaml document
+func parseChartMetadata(data []byte) (*chartMetadata, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &chartMetadata{
+		Name:        doc.get("name").text(),
+		Version:     doc.get("version").text(),
+		AppVersion:  doc.get("appVersion").text(),
+		Description: doc.get("description").text(),
+		Home:        doc.get("home").text(),
+		Icon:        doc.get("icon").text(),
+		KubeVersion: strings.TrimSpace(doc.get("kubeVersion").text()),
+		Type:        strings.TrimSpace(doc.get("type").text()),
+	}
+	if metadata.Type == "" {
+		metadata.Type = chartTypeApplication
+	}
+
+	if sources := doc.get("sources"); sources != nil && sources.kind == yamlSeq {
+		for _, source := range sources.items {
+			if url := strings.TrimSpace(source.text()); url != "" {
+				metadata.Sources = append(metadata.Sources, url)
+			}
+		}
+	}
+
+	metadata.Deprecated, _ = doc.get("deprecated").toValue().(bool)
+	if annotations := doc.get("annotations"); annotations != nil && annotations.kind == yamlMap {
+		for i, key := range annotations.keys {
+			note := strings.TrimSpace(annotations.items[i].text())
+			metadata.Annotations = append(metadata.Annotations, chartAnnotation{Key: key, Value: note})
+			if !strings.Contains(strings.ToLower(key), "deprecat") || note == "" || strings.EqualFold(note, "false") {
+				continue
+			}
+			metadata.Deprecated = true
+			if !strings.EqualFold(note, "true") {
+				metadata.DeprecationNote = note
+			}
+		}
+	}
+
+	if deps := doc.get("dependencies"); deps != nil && deps.kind == yamlSeq {
+		for _, dep := range deps.items {
+			metadata.Dependencies = append(metadata.Dependencies, chartDependency{
+				Name:    dep.get("name").text(),
+				Version: dep.get("version").text(),
+			})
+		}
+	}
+	if maintainers := doc.get("maintainers"); maintainers != nil && maintainers.kind == yamlSeq {
+		for _, maintainer := range maintainers.items {
+			metadata.Maintainers = append(metadata.Maintainers, chartMaintainer{
+				Name:  maintainer.get("name").text(),
+				Email: maintainer.get("email").text(),
+				URL:   maintainer.get("url").text(),
+			})
+		}
+	}
+	return metadata, nil
+}
+
+// withDetails requests the metadata of the highlighted version when the
+// details panel is open or a Kubernetes version is being checked, and of the
+// newest version for the deprecation banner, when they have not been loaded yet
+func (m model) withDetails() (model, tea.Cmd) {
+	if m.state != stateVersionList || m.loading || len(m.versions) == 0 {
+		return m, nil
+	}
+
+	var cmds []tea.Cmd
+	wanted := []HelmVersion{m.versions[0]}
+	if (m.showDetails || m.kubeVersion != "") && m.cursor < len(m.versions) {
+		wanted = append(wanted, m.versions[m.cursor])
+	}
+	for _, version := range wanted {
+		key := downloadKey(version.Name, version.Version)
+		if _, requested := m.details[key]; requested {
+			continue
+		}
+
+		// A nil entry marks the request as in flight
+		m.details[key] = nil
+		cmds = append(cmds, loadDetails(version.Name, version.Version))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// deprecationBanner warns when the chart is deprecated, going by the
+// newest version or the highlighted one
+func (m model) deprecationBanner() string {
+	if len(m.versions) == 0 {
+		return ""
+	}
+
+	candidates := []HelmVersion{m.versions[0]}
+	if m.cursor < len(m.versions) {
+		candidates = append(candidates, m.versions[m.cursor])
+	}
+	for _, version := range candidates {
+		metadata := m.details[downloadKey(version.Name, version.Version)]
+		if metadata == nil || !metadata.Deprecated {
+			continue
+		}
+		banner := fmt.Sprintf("⛔ DEPRECATED: %s %s is marked as deprecated", version.Name, version.Version)
+		if metadata.DeprecationNote != "" {
+			banner += " — " + metadata.DeprecationNote
+		}
+		return errorStyle.Render(banner)
+	}
+	return ""
+}
+
+// renderDetails draws the details panel for the highlighted version
+func (m model) renderDetails() string {
+	if m.cursor >= len(m.versions) {
+		return ""
+	}
+
+	version := m.versions[m.cursor]
+	metadata := m.details[downloadKey(version.Name, version.Version)]
+
+	var s strings.Builder
+	s.WriteString(selectedStyle.Render(fmt.Sprintf("ℹ️  %s %s", version.Name, version.Version)) + "\n")
+
+	switch {
+	case metadata == nil:
+		s.WriteString("   🔄 Loading details...\n")
+	case metadata.err != nil:
+		s.WriteString("   " + errorStyle.Render(metadata.err.Error()) + "\n")
+	default:
+		if metadata.Deprecated {
+			note := "   ⛔ Deprecated"
+			if metadata.DeprecationNote != "" {
+				note += ": " + metadata.DeprecationNote
+			}
+			s.WriteString(errorStyle.Render(note) + "\n")
+		}
+		if metadata.Description != "" {
+			s.WriteString("   " + metadata.Description + "\n")
+		}
+		if metadata.Type == chartTypeLibrary {
+			s.WriteString("   " + develBadgeStyle.Render("📚 Library chart: it provides templates to other charts and cannot be installed on its own") + "\n")
+		} else {
+			s.WriteString("   Type: " + metadata.Type + "\n")
+		}
+		if line, incompatible := m.kubeCompatibility(metadata); incompatible {
+			s.WriteString("   " + errorStyle.Render(line) + "\n")
+		} else {
+			s.WriteString("   " + line + "\n")
+		}
+		if metadata.Home != "" {
+			s.WriteString("   Home: " + hyperlink(metadata.Home, metadata.Home) + "\n")
+		}
+		if len(metadata.Sources) == 1 {
+			s.WriteString("   Source: " + hyperlink(metadata.Sources[0], metadata.Sources[0]) + "\n")
+		} else if len(metadata.Sources) > 1 {
+			s.WriteString("   Sources:\n")
+			for _, source := range metadata.Sources {
+				s.WriteString("     • " + hyperlink(source, source) + "\n")
+			}
+		}
+		if metadata.Icon != "" {
+			s.WriteString("   Icon: " + hyperlink(metadata.Icon, metadata.Icon) + "\n")
+		}
+		if len(metadata.Dependencies) > 0 {
+			deps := make([]string, len(metadata.Dependencies))
+			for i, dep := range metadata.Dependencies {
+				deps[i] = strings.TrimSpace(dep.Name + " " + dep.Version)
+			}
+			s.WriteString("   Dependencies: " + strings.Join(deps, ", ") + " • Subchart values: c\n")
+		}
+		if len(metadata.Maintainers) > 0 {
+			s.WriteString("   Maintainers:\n")
+			for _, maintainer := range metadata.Maintainers {
+				s.WriteString("     • " + renderMaintainer(maintainer) + "\n")
+			}
+		}
+		if len(metadata.Annotations) > 0 {
+			s.WriteString("   Annotations:\n")
+			for _, annotation := range metadata.Annotations {
+				s.WriteString("     • " + annotation.Key + ": " + shorten(strings.Join(strings.Fields(annotation.Value), " "), annotationWidth) + "\n")
+			}
+		}
+	}
+
+	return s.String()
+}
+
+// renderMaintainer formats a maintainer, linking the email as a mailto: link
+// where the terminal supports it
+func renderMaintainer(maintainer chartMaintainer) string {
+	parts := []string{}
+	if maintainer.Name != "" {
+		parts = append(parts, maintainer.Name)
+	}
+	if maintainer.Email != "" {
+		email := maintainer.Email
+		if hyperlinksSupported() {
+			email = hyperlink("mailto:"+email, email)
+		}
+		parts = append(parts, "<"+email+">")
+	}
+	if maintainer.URL != "" {
+		parts = append(parts, hyperlink(maintainer.URL, maintainer.URL))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecentErrors is how many errors the recent errors panel keeps
+const maxRecentErrors = 50
+
+// recentError is an error seen during the session
+type recentError struct {
+	time    time.Time
+	command bool // a failed command rather than an error shown on screen
+	text    string
+}
+
+// errorLog keeps the last maxRecentErrors errors in memory, including
+// failures that were recovered from without being shown. Commands fail in
+// background goroutines, so access is serialised with a mutex.
+type errorLog struct {
+	mu      sync.Mutex
+	entries []recentError
+}
+
+// recentErrors is the session's error log, shown with !
+var recentErrors = &errorLog{}
+
+// add records an error, dropping the oldest once the log is full
+func (l *errorLog) add(command bool, text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, recentError{time: time.Now(), command: command, text: text})
+	if extra := len(l.entries) - maxRecentErrors; extra > 0 {
+		l.entries = append([]recentError(nil), l.entries[extra:]...)
+	}
+}
+
+// list returns the recorded errors, oldest first
+func (l *errorLog) list() []recentError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]recentError(nil), l.entries...)
+}
+
+// openRecentErrors shows the recent errors in a scrollable panel, newest first
+func (m model) openRecentErrors() model {
+	entries := recentErrors.list()
+	if len(entries) == 0 {
+		m.status = helpStyle.Render("No errors so far")
+		return m
+	}
+
+	var s strings.Builder
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		icon := "❌"
+		if entry.command {
+			icon = "⚙️ "
+		}
+		text := strings.ReplaceAll(strings.TrimSpace(entry.text), "\n", "\n           ")
+		s.WriteString(fmt.Sprintf("%s %s %s\n", entry.time.Format("15:04:05"), icon, text))
+	}
+
+	m = m.push()
+	m.state = statePreview
+	m.preview = newViewport([]byte(s.String()), m.viewportHeight())
+	m.preview.title = fmt.Sprintf("🧾 Recent errors, newest first (%d; ⚙️  failed command, ❌ shown on screen):", len(entries))
+	return m
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// isFavorite reports whether a repository, or a chart by its full name, is
+// one of the favorites
+func (m model) isFavorite(name string) bool {
+	for _, favorite := range m.cfg.Favorites {
+		if favorite == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFavorite marks the highlighted repository or chart as a favorite,
+// or unmarks it, and saves the favorites to the configuration file
+func (m model) toggleFavorite() (tea.Model, tea.Cmd) {
+	name := m.highlighted()
+	if name == "" {
+		return m, nil
+	}
+
+	// Copy the favorites so earlier model values keep their own
+	favorites := make([]string, 0, len(m.cfg.Favorites)+1)
+	for _, favorite := range m.cfg.Favorites {
+		if favorite != name {
+			favorites = append(favorites, favorite)
+		}
+	}
+	if !m.isFavorite(name) {
+		favorites = append(favorites, name)
+	}
+	if len(favorites) == 0 {
+		favorites = nil
+	}
+	m.cfg.Favorites = favorites
+
+	save := saveConfigCmd(m.opts.configPath, m.cfg, "favorites")
+	if !m.favoritesFirst {
+		return m, save
+	}
+	if m.state == stateRepoList {
+		return m.applyRepoView(), save
+	}
+	m, cmd := m.resortCharts()
+	return m, tea.Batch(save, cmd)
+}
+
+// toggleFavoritesFirst floats the favorites to the top of the repository
+// and chart lists, or puts them back in their place
+func (m model) toggleFavoritesFirst() (tea.Model, tea.Cmd) {
+	m.favoritesFirst = !m.favoritesFirst
+	if m.state == stateRepoList {
+		return m.applyRepoView(), nil
+	}
+	return m.resortCharts()
+}
+
+// favoritesSeparator returns the line drawn between the favorites and the
+// other items at row i of a list ordered favorites first, or ""
+func (m model) favoritesSeparator(i, start int, name func(int) string) string {
+	if !m.favoritesFirst || i == start || !m.isFavorite(name(i-1)) || m.isFavorite(name(i)) {
+		return ""
+	}
+	return skeletonStyle.Render("       " + strings.Repeat("─", 40))
+}
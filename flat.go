@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flattenValues renders a values document as one key.subkey=value line per
+// leaf, in the syntax of helm's --set flag. List items are addressed as
+// key[0]; empty maps and lists are written as {} and [].
+func flattenValues(doc *yamlNode) []byte {
+	var lines []string
+	if doc != nil && doc.kind != yamlScalar {
+		flattenNode("", doc, &lines)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// flattenNode appends the lines for n, whose path is prefix
+func flattenNode(prefix string, n *yamlNode, lines *[]string) {
+	switch n.kind {
+	case yamlMap:
+		if len(n.keys) == 0 {
+			*lines = append(*lines, prefix+"={}")
+			return
+		}
+		for i, key := range n.keys {
+			path := escapeSetKey(key)
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			flattenNode(path, n.items[i], lines)
+		}
+	case yamlSeq:
+		if len(n.items) == 0 {
+			*lines = append(*lines, prefix+"=[]")
+			return
+		}
+		for i, item := range n.items {
+			flattenNode(fmt.Sprintf("%s[%d]", prefix, i), item, lines)
+		}
+	default:
+		*lines = append(*lines, prefix+"="+setValue(n))
+	}
+}
+
+// setValue writes a scalar for --set. Booleans and integers are written the
+// way --set reads them, so yes and 0755 keep the types they have in values.
+func setValue(n *yamlNode) string {
+	switch value := n.toValue().(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	}
+	return escapeSetValue(n.value)
+}
+
+// escapeSetKey escapes the characters that separate keys in --set syntax
+func escapeSetKey(key string) string {
+	return strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`, "=", `\=`, ",", `\,`).Replace(key)
+}
+
+// escapeSetValue escapes the characters that end a value in --set syntax and
+// keeps multi-line strings on one line
+func escapeSetValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// repoCellWidth is the width of one repository cell in the grid, including
+// the gap to the next column
+const repoCellWidth = 66
+
+// maxRepoColumns is the most columns the repository grid flows into
+const maxRepoColumns = 3
+
+// repoColumns returns how many columns the repository list is drawn in: more
+// than one only with --repo-grid on a terminal wide enough, and never while
+// the list is split into sections
+func (m model) repoColumns() int {
+	if !m.opts.repoGrid || m.groupRepos || m.state != stateRepoList {
+		return 1
+	}
+	columns := m.width / repoCellWidth
+	if columns < 1 {
+		return 1
+	}
+	if columns > maxRepoColumns {
+		return maxRepoColumns
+	}
+	return columns
+}
+
+// pageLen returns the number of items on a page: pageSize rows per column,
+// or the rows in view per column with --no-paging
+func (m model) pageLen() int {
+	return m.gridRows() * m.repoColumns()
+}
+
+// gridRows returns how many rows each column of the list holds
+func (m model) gridRows() int {
+	if m.opts.noPaging {
+		return m.listHeight()
+	}
+	return pageSize
+}
+
+// moveColumn moves the cursor to the same row of the next or previous grid
+// column, staying put at the edges of the list
+func (m model) moveColumn(delta int) model {
+	if m.repoColumns() == 1 {
+		return m
+	}
+	target := m.cursor + delta*m.gridRows()
+	if target >= 0 && target < len(m.repos) {
+		m.cursor = target
+	}
+	return m
+}
+
+// repoGrid renders the repositories of the current page in columns, filled
+// top to bottom. Only the cells the number keys reach are numbered.
+func (m model) repoGrid() string {
+	columns := m.repoColumns()
+	rows := m.gridRows()
+	start := m.getPageStart()
+	end := m.getPageEnd(len(m.repos))
+
+	// name and URL share the cell after the marker and number
+	urlWidth := repoCellWidth - 2 - 4 - 1 - 20 - 1 - 2
+
+	var s strings.Builder
+	for c := 0; c < columns; c++ {
+		s.WriteString(gridCell(fmt.Sprintf("%-4s %-20s %s", "", "REPOSITORY", "URL"), c < columns-1))
+	}
+	s.WriteString("\n")
+	for c := 0; c < columns; c++ {
+		s.WriteString(gridCell(fmt.Sprintf("%-4s %-20s %s", "────", "────────────────────", strings.Repeat("─", urlWidth)), c < columns-1))
+	}
+	s.WriteString("\n")
+
+	for row := 0; row < rows && start+row < end; row++ {
+		for c := 0; c < columns; c++ {
+			i := start + c*rows + row
+			if i >= end {
+				break
+			}
+			repo := m.repos[i]
+			key := rowKey{state: m.state, index: i, selected: i == m.cursor, width: m.width}
+			numStr := m.rowLabel(i)
+			favorite := m.isFavorite(repo.Name)
+			cell := m.rows.row(key, rowData("grid", numStr, repo.Name, repo.URL, fmt.Sprint(favorite)), func() string {
+				repoName := chartVersionStyle.Render(fmt.Sprintf("%-20s", repo.Name))
+
+				// The star takes its room from the URL so the cell keeps its width
+				line := fmt.Sprintf("%-4s %s %s", numStr, repoName, appVersionStyle.Render(shorten(repo.URL, urlWidth)))
+				if favorite {
+					line = fmt.Sprintf("%-4s %s %s %s", numStr, repoName, appVersionStyle.Render(shorten(repo.URL, urlWidth-2)), groupHeaderStyle.Render("★"))
+				}
+
+				if i == m.cursor {
+					return selectedStyle.Render("► " + line)
+				}
+				return "  " + line
+			})
+			s.WriteString(gridCell(cell, c < columns-1 && i+rows < end))
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// gridCell pads a rendered cell to the cell width when another column follows
+func gridCell(cell string, pad bool) string {
+	if !pad {
+		return cell
+	}
+	if gap := repoCellWidth - lipgloss.Width(cell); gap > 0 {
+		return cell + strings.Repeat(" ", gap)
+	}
+	return cell
+}
+
+// shorten cuts text to at most width runes, marking the cut with …
+func shorten(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width-1]) + "…"
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gutter returns the --gutter column drawn before row i of a list of total
+// items: the cursor's position in the whole list, e.g. 47/213, on the
+// cursor's row and blanks on every other row. Headers pass -1. Without
+// --gutter it is empty.
+func (m model) gutter(i, total int) string {
+	if !m.opts.gutter {
+		return ""
+	}
+	width := len(fmt.Sprintf("%d/%d", total, total))
+	if i != m.cursor {
+		return strings.Repeat(" ", width+1)
+	}
+	return appVersionStyle.Render(fmt.Sprintf("%*s", width, fmt.Sprintf("%d/%d", i+1, total))) + " "
+}
+
+// gutterBlock indents every line of a list header by the --gutter width so
+// it stays aligned with the rows
+func (m model) gutterBlock(text string, total int) string {
+	if !m.opts.gutter {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = m.gutter(-1, total) + line
+		}
+	}
+	return strings.Join(lines, "")
+}
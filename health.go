@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// repoCheckClient fetches repository indexes for the reachability check
+var repoCheckClient = &http.Client{Timeout: 10 * time.Second, Transport: newTransport(nil)}
+
+// repoHealth is the result of checking one repository; err is nil when it
+// is reachable
+type repoHealth struct {
+	repo HelmRepo
+	err  error
+}
+
+// healthProgressMsg reports one more repository checked
+type healthProgressMsg struct {
+	ch <-chan tea.Msg
+}
+
+// healthDoneMsg carries the results of the reachability check
+type healthDoneMsg []repoHealth
+
+// checkRepo fetches the index of a repository to see whether it answers
+func checkRepo(repo HelmRepo) error {
+	req, err := http.NewRequestWithContext(appContext, http.MethodGet, strings.TrimSuffix(repo.URL, "/")+"/index.yaml", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := repoCheckClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// The URL is shown next to the error already
+		return urlErr.Err
+	}
+	if err != nil {
+		return err
+	}
+	// Only the status matters, so the index itself is not read
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("index.yaml returned %s", resp.Status)
+	}
+	return nil
+}
+
+// checkRepos checks every repository in parallel, at most limit at a time,
+// reporting each one as it is done
+func checkRepos(repos []HelmRepo, limit int) tea.Cmd {
+	ch := make(chan tea.Msg)
+
+	go func() {
+		results := make([]repoHealth, len(repos))
+		runPool(limit, len(repos), func(i int) {
+			results[i] = repoHealth{repo: repos[i], err: checkRepo(repos[i])}
+			ch <- healthProgressMsg{ch: ch}
+		})
+		ch <- healthDoneMsg(results)
+		close(ch)
+	}()
+
+	return waitForHealth(ch)
+}
+
+// waitForHealth waits for the next check to finish or the results
+func waitForHealth(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// startHealthCheck checks all repositories, in the background unless the
+// reachability view is open
+func (m model) startHealthCheck() (model, tea.Cmd) {
+	if m.checking || len(m.allRepos) == 0 {
+		return m, nil
+	}
+	m.checking = true
+	m.checked = 0
+	return m, checkRepos(m.allRepos, m.opts.concurrency)
+}
+
+// openHealth shows the repositories grouped by reachability, checking them
+// first unless a check already ran
+func (m model) openHealth() (tea.Model, tea.Cmd) {
+	m = m.push()
+	m.state = stateRepoHealth
+	m.cursor = 0
+	if m.health != nil || m.checking {
+		return m, nil
+	}
+	m, cmd := m.startHealthCheck()
+	return m, cmd
+}
+
+// updateHealthDone stores the check results, unreachable repositories
+// first, and hides those with hide unreachable on
+func (m model) updateHealthDone(msg healthDoneMsg) model {
+	m.checking = false
+	m.health = []repoHealth(msg)
+	sort.SliceStable(m.health, func(i, j int) bool {
+		return m.health[i].err != nil && m.health[j].err == nil
+	})
+	m.unreachable = make(map[string]bool)
+	for _, result := range m.health {
+		if result.err != nil {
+			m.unreachable[result.repo.Name] = true
+		}
+	}
+	m.rows = make(rowCache)
+	m = m.applyRepoView()
+	return m
+}
+
+// hiddenUnreachable counts the repositories left out of the repository list
+// as unreachable
+func (m model) hiddenUnreachable() int {
+	if !m.hideUnreachable {
+		return 0
+	}
+	return len(m.unreachable)
+}
+
+// toggleHideUnreachable hides or shows the unreachable repositories in the
+// repository list for the rest of the session
+func (m model) toggleHideUnreachable() model {
+	m.hideUnreachable = !m.hideUnreachable
+	m.rows = make(rowCache)
+	m = m.applyHealthView()
+	if m.hideUnreachable {
+		m.status = helpStyle.Render("🙈 Unreachable repositories are hidden for this session (S keeps it that way)")
+	} else {
+		m.status = helpStyle.Render("👀 Unreachable repositories are shown for this session (S keeps it that way)")
+	}
+	return m
+}
+
+// applyHealthView rebuilds the repository list kept under the reachability
+// view so it hides or shows the unreachable repositories
+func (m model) applyHealthView() model {
+	if len(m.navStack) == 0 {
+		return m
+	}
+	state, cursor := m.state, m.cursor
+	m.state = stateRepoList
+	m = m.applyRepoView()
+	m.state, m.cursor = state, cursor
+	return m
+}
+
+// saveHideUnreachable keeps the hide unreachable choice in the config file,
+// so later sessions check the repositories at startup and hide those that
+// do not answer
+func (m model) saveHideUnreachable() (model, tea.Cmd) {
+	m.cfg.HideUnreachable = m.hideUnreachable
+	return m, saveConfigCmd(m.opts.configPath, m.cfg, "hide unreachable setting")
+}
+
+// openHealthRepo leaves the reachability view for the chart list of a
+// repository
+func (m model) openHealthRepo(index int) (tea.Model, tea.Cmd) {
+	name := m.health[index].repo.Name
+	m, _ = m.back()
+	i := repoIndex(m.repos, name)
+	if i < 0 {
+		m.status = errorStyle.Render(fmt.Sprintf("⚠️  %s is hidden, press u in the reachability view to show it", name))
+		return m, nil
+	}
+	m.cursor = i
+	return m.openRepo(i)
+}
+
+// renderHealth draws the repositories in an unreachable and a reachable group
+func (m model) renderHealth() string {
+	var s strings.Builder
+	if m.checking {
+		s.WriteString(fmt.Sprintf("🩺 Checking repositories... %d/%d\n", m.checked, len(m.allRepos)))
+		return s.String()
+	}
+	if len(m.health) == 0 {
+		s.WriteString("📭 No repositories to check.\n")
+		return s.String()
+	}
+
+	unreachable := len(m.unreachable)
+	s.WriteString(fmt.Sprintf("🩺 %d of %d repositories reachable:\n\n", len(m.health)-unreachable, len(m.health)))
+
+	start := m.getPageStart()
+	end := m.getPageEnd(len(m.health))
+	for i := start; i < end; i++ {
+		result := m.health[i]
+		if i == start || i == unreachable {
+			header := fmt.Sprintf("✅ Reachable (%d)", len(m.health)-unreachable)
+			if i < unreachable {
+				header = fmt.Sprintf("❌ Unreachable (%d)", unreachable)
+			}
+			s.WriteString(m.gutterBlock(groupHeaderStyle.Render(header)+"\n", len(m.health)))
+		}
+
+		detail := result.repo.URL
+		if result.err != nil {
+			detail += " — " + result.err.Error()
+		}
+		name := chartVersionStyle.Render(fmt.Sprintf("%-20s", result.repo.Name))
+		if result.err != nil {
+			name = errorStyle.Render(fmt.Sprintf("%-20s", result.repo.Name))
+		}
+		line := fmt.Sprintf("%-4s %s %s", m.rowLabel(i), name, appVersionStyle.Render(detail))
+		s.WriteString(m.gutter(i, len(m.health)))
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("► "+line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	info := "👀 Unreachable repositories are shown in the repository list"
+	if m.hideUnreachable {
+		info = "🙈 Unreachable repositories are hidden from the repository list"
+	}
+	if rows := m.scrollInfo(len(m.health), "repositories"); rows != "" {
+		info = rows + " • " + info
+	} else if totalPages := m.getTotalPages(); totalPages > 1 {
+		info = fmt.Sprintf("📄 Page %d of %d • %s", m.getCurrentPage()+1, totalPages, info)
+	}
+	s.WriteString(helpStyle.Render(info))
+	return s.String()
+}
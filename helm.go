@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultHelmTimeout is how long a single helm command may run when
+// --helm-timeout is not given
+const defaultHelmTimeout = 5 * time.Minute
+
+// commandWaitDelay bounds how long a cancelled command may take to exit
+// before its output pipes are closed, and how long shutdown waits
+const commandWaitDelay = 2 * time.Second
+
+// appContext is cancelled when the program exits or is interrupted, which
+// kills the helm processes still running instead of leaving them behind
+var appContext, cancelApp = context.WithCancel(context.Background())
+
+// commandTimeout limits each command; main sets it from --helm-timeout
+var commandTimeout = defaultHelmTimeout
+
+// running counts the commands started with runCommand, so shutdown can wait
+// for them to be stopped
+var running sync.WaitGroup
+
+// commandError describes a failed command: what was run, why it failed and
+// what it printed on stderr
+type commandError struct {
+	args   []string
+	stderr string
+	err    error
+}
+
+// Error reads like: helm pull --version 1.0.0 -- bitnami/redis: exit status 1: Error: chart not found
+func (e *commandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", strings.Join(e.args, " "), e.err)
+	if e.stderr != "" {
+		msg += ": " + e.stderr
+	}
+	return msg
+}
+
+// Unwrap exposes the underlying error, e.g. context.DeadlineExceeded
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+// HelmRunner runs helm commands. Every command goes through helmRunner, so
+// tests can swap in a fake that returns canned output instead of needing a
+// helm binary.
+type HelmRunner interface {
+	// Run runs helm with the arguments and returns its stdout
+	Run(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// execHelm is the HelmRunner that runs the installed helm binary
+type execHelm struct{}
+
+// Run runs helm as a subprocess. The process is killed when ctx is cancelled
+// or the command timeout passes; failures are returned as a *commandError.
+func (execHelm) Run(ctx context.Context, args ...string) ([]byte, error) {
+	return runCommand(ctx, "helm", args...)
+}
+
+// helmRunner is the HelmRunner used by all commands
+var helmRunner HelmRunner = execHelm{}
+
+// runHelm runs helm with the given arguments through helmRunner and returns
+// its stdout. Commands that reach the network are retried with --retries.
+func runHelm(ctx context.Context, args ...string) ([]byte, error) {
+	if !isNetworkCommand(args) {
+		return helmRunner.Run(ctx, args...)
+	}
+	return withRetries(ctx, args, func() ([]byte, error) {
+		return helmRunner.Run(ctx, args...)
+	})
+}
+
+// runCommand runs any external command the way runHelm runs helm, logging
+// it to the session log
+func runCommand(parent context.Context, name string, args ...string) ([]byte, error) {
+	running.Add(1)
+	defer running.Done()
+
+	ctx, cancel := context.WithTimeout(parent, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	output, err := logRun(cmd, cmd.Output)
+	if err == nil {
+		return output, nil
+	}
+
+	cmdErr := &commandError{args: cmd.Args, stderr: commandStderr(err), err: err}
+	switch {
+	case parent.Err() != nil:
+		// Killed because the caller gave up, e.g. on quit
+		cmdErr.err = parent.Err()
+	case ctx.Err() != nil:
+		cmdErr.err = fmt.Errorf("timed out after %s: %w", commandTimeout, ctx.Err())
+	}
+	return output, cmdErr
+}
+
+// commandStderr returns the trimmed stderr of a failed command, or "" when
+// the error did not come from the command exiting
+func commandStderr(err error) string {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.stderr
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return ""
+}
+
+// shutdown cancels the commands still running, waits a moment for them to
+// exit and closes the session log
+func shutdown() {
+	cancelApp()
+
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(commandWaitDelay):
+	}
+
+	sessionLog.close()
+}
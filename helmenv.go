@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helmEnv holds the paths helm reports with helm env, so features reading
+// helm's files directly find them where helm keeps them instead of guessing
+// from HOME or XDG_* variables
+type helmEnv struct {
+	RepositoryCache  string // HELM_REPOSITORY_CACHE, the cached repository indexes
+	RepositoryConfig string // HELM_REPOSITORY_CONFIG, repositories.yaml
+	RegistryConfig   string // HELM_REGISTRY_CONFIG, helm registry login credentials
+	CacheHome        string // HELM_CACHE_HOME
+	ConfigHome       string // HELM_CONFIG_HOME
+	DataHome         string // HELM_DATA_HOME
+}
+
+// helmEnvMsg carries the environment read at startup
+type helmEnvMsg helmEnv
+
+// parseHelmEnv reads the KEY="value" lines helm env prints
+func parseHelmEnv(output []byte) helmEnv {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		vars[key] = value
+	}
+	return helmEnv{
+		RepositoryCache:  vars["HELM_REPOSITORY_CACHE"],
+		RepositoryConfig: vars["HELM_REPOSITORY_CONFIG"],
+		RegistryConfig:   vars["HELM_REGISTRY_CONFIG"],
+		CacheHome:        vars["HELM_CACHE_HOME"],
+		ConfigHome:       vars["HELM_CONFIG_HOME"],
+		DataHome:         vars["HELM_DATA_HOME"],
+	}
+}
+
+// repositoryIndex returns where helm caches the index of a repository, or
+// "" when the cache directory is unknown
+func (e helmEnv) repositoryIndex(repo string) string {
+	if e.RepositoryCache == "" {
+		return ""
+	}
+	return filepath.Join(e.RepositoryCache, repo+"-index.yaml")
+}
+
+// helmEnvCache keeps the environment once helm env succeeded. Commands
+// running without a model, such as OCI logins in non-interactive mode,
+// read it from here.
+var helmEnvCache struct {
+	mu     sync.Mutex
+	env    helmEnv
+	loaded bool
+}
+
+// helmEnvironment returns helm's environment, running helm env the first
+// time. A failure returns an empty environment and is retried next time.
+func helmEnvironment() helmEnv {
+	helmEnvCache.mu.Lock()
+	defer helmEnvCache.mu.Unlock()
+	if !helmEnvCache.loaded {
+		output, err := runHelm(appContext, "env")
+		if err != nil {
+			return helmEnv{}
+		}
+		helmEnvCache.env = parseHelmEnv(output)
+		helmEnvCache.loaded = true
+	}
+	return helmEnvCache.env
+}
+
+// loadHelmEnv reads helm's environment at startup
+func loadHelmEnv() tea.Cmd {
+	return func() tea.Msg {
+		return helmEnvMsg(helmEnvironment())
+	}
+}
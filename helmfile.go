@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultHelmfileName is the file the Helmfile export suggests
+const defaultHelmfileName = "helmfile-releases.yaml"
+
+// helmfileReleases renders the selected versions as Helmfile repositories
+// and releases. Release names and namespaces are placeholders to edit.
+func (m model) helmfileReleases() string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("# Exported by helm-browser on %s\n", time.Now().Format(time.RFC3339)))
+	s.WriteString("# Release names and namespaces are placeholders: review them before running helmfile\n")
+
+	keys := m.selectedKeys()
+	seen := map[string]bool{}
+	var repos []HelmRepo
+	for _, key := range keys {
+		repo := m.repoFor(m.selected[key].Name)
+		if !seen[repo.Name] && repo.URL != "" && !isOCIRef(repo.URL) {
+			seen[repo.Name] = true
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) > 0 {
+		s.WriteString("repositories:\n")
+		for _, repo := range repos {
+			s.WriteString(fmt.Sprintf("  - name: %s\n    url: %s\n", doubleQuote(repo.Name), doubleQuote(repo.URL)))
+		}
+	}
+
+	s.WriteString("releases:\n")
+	names := map[string]int{}
+	for _, key := range keys {
+		version := m.selected[key]
+		chartParts := strings.Split(version.Name, "/")
+		name := chartParts[len(chartParts)-1]
+		// Two versions of one chart get distinct release names
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, names[name])
+		}
+		s.WriteString(fmt.Sprintf("  - name: %s\n", doubleQuote(name)))
+		s.WriteString(fmt.Sprintf("    namespace: %s\n", doubleQuote("default")))
+		s.WriteString(fmt.Sprintf("    chart: %s\n", doubleQuote(version.Name)))
+		s.WriteString(fmt.Sprintf("    version: %s\n", doubleQuote(version.Version)))
+	}
+	return s.String()
+}
+
+// openHelmfileExport asks where to write the Helmfile releases of the
+// selected versions
+func (m model) openHelmfileExport() model {
+	if len(m.selected) == 0 {
+		m.status = errorStyle.Render("⚠️  Nothing to export yet: select versions with x first")
+		return m
+	}
+	m = m.openInput(inputHelmfile, fmt.Sprintf("📋 Write Helmfile releases for %d versions to (empty copies them):", len(m.selected)))
+	m.input.value = defaultHelmfileName
+	return m
+}
+
+// submitHelmfileExport writes the Helmfile releases to path, or copies them
+// to the clipboard when path is empty. An existing file is only replaced
+// with --force.
+func (m model) submitHelmfileExport(path string) (tea.Model, tea.Cmd) {
+	text := m.helmfileReleases()
+	if path == "" {
+		m.input = inputPrompt{}
+		return m, func() tea.Msg {
+			return clipboardMsg{text: fmt.Sprintf("Helmfile releases for %d versions", len(m.selected)), err: copyToClipboard(text)}
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil && !m.opts.force {
+		m.input.err = fmt.Sprintf("%s already exists (use --force to replace it)", path)
+		return m, nil
+	}
+	m.input = inputPrompt{}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("❌ Export failed: %v", err))
+		return m, nil
+	}
+	m.status = downloadedStyle.Render(fmt.Sprintf("📋 Helmfile releases for %d versions written to %s", len(m.selected), path))
+	return m, nil
+}
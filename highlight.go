@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// YAML syntax highlighting styles for the values preview
+var (
+	yamlKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39"))
+
+	yamlStringStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("114"))
+
+	yamlNumberStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("176"))
+
+	yamlLiteralStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("215"))
+
+	yamlCommentStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("243")).
+				Italic(true)
+)
+
+// highlightYAML colours one line of YAML. It works line by line so only the
+// lines visible in the preview need to be highlighted.
+func highlightYAML(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(trimmed)]
+
+	if strings.HasPrefix(trimmed, "#") {
+		return indent + yamlCommentStyle.Render(trimmed)
+	}
+
+	body := stripComment(trimmed)
+	comment := strings.TrimPrefix(trimmed, body)
+
+	var s strings.Builder
+	s.WriteString(indent)
+
+	for strings.HasPrefix(body, "- ") || body == "-" {
+		s.WriteString("- ")
+		body = strings.TrimPrefix(strings.TrimPrefix(body, "-"), " ")
+	}
+
+	if colon := findMappingColon(body); colon >= 0 {
+		s.WriteString(yamlKeyStyle.Render(body[:colon]) + ":")
+		value := body[colon+1:]
+		s.WriteString(value[:len(value)-len(strings.TrimLeft(value, " "))])
+		body = strings.TrimLeft(value, " ")
+	}
+
+	s.WriteString(highlightScalar(body))
+
+	if comment != "" {
+		s.WriteString(yamlCommentStyle.Render(comment))
+	}
+	return s.String()
+}
+
+// highlightScalar colours a value according to its YAML type
+func highlightScalar(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	switch value[0] {
+	case '"', '\'':
+		return yamlStringStyle.Render(value)
+	case '|', '>', '&', '*', '{', '[':
+		return yamlLiteralStyle.Render(value)
+	}
+
+	switch (&yamlNode{kind: yamlScalar, value: value}).toValue().(type) {
+	case nil, bool:
+		return yamlLiteralStyle.Render(value)
+	case int64, float64:
+		return yamlNumberStyle.Render(value)
+	}
+	return value
+}
+
+// stripComment removes a trailing comment that is outside any quotes
+func stripComment(text string) string {
+	if strings.HasPrefix(text, "#") {
+		return ""
+	}
+
+	inSingle, inDouble := false, false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && inDouble:
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case c == '#' && !inSingle && !inDouble && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+	return strings.TrimRight(text, " \t")
+}
+
+// findMappingColon returns the index of the ": " separating a key from its value,
+// or -1 when text is not a mapping entry
+func findMappingColon(text string) int {
+	if text == "" || strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") || strings.HasPrefix(text, "- ") || text == "-" {
+		return -1
+	}
+
+	inSingle, inDouble := false, false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && inDouble:
+			i++
+		case c == '\'' && !inDouble && (i == 0 || inSingle):
+			inSingle = !inSingle
+		case c == '"' && !inSingle && (i == 0 || inDouble):
+			inDouble = !inDouble
+		case c == ':' && !inSingle && !inDouble && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
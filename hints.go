@@ -0,0 +1,93 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// errorHint suggests what to do about an error. Hints are written in a small
+// subset of markdown: `code`, **bold**, [links](url) and "- " list items.
+type errorHint struct {
+	matches []string
+	hint    string
+}
+
+// errorHints are tried in order; the first whose text appears in the error wins
+var errorHints = []errorHint{
+	{[]string{"no repo named", "no repositories", "failed to list repos"}, "The repository is not configured. Add it first:\n" +
+		"- `helm repo add <name> <url>`, then `helm repo update`\n" +
+		"- or find one on [Artifact Hub](https://artifacthub.io) and add it with **--artifacthub**"},
+	{[]string{"no such host", "connection refused", "i/o timeout", "network is unreachable", "tls handshake timeout"}, "The repository could not be reached:\n" +
+		"- check your network, or pass **--proxy** when you are behind a proxy\n" +
+		"- press `R` in the repository list to see which repositories answer\n" +
+		"- remove one that is gone with `helm repo remove <name>`"},
+	{[]string{"permission denied", "read-only file system"}, "Check that you can write to the output directory, or choose another with **--output-dir**."},
+	{[]string{"unauthorized", "forbidden", "authentication required"}, "The registry refused the request. Log in with `helm registry login <host>`, or add the repository again with `helm repo add --username <user> --password-stdin`."},
+	{[]string{"helm-diff", "unknown command \"diff\""}, "Install the [helm-diff plugin](https://github.com/databus23/helm-diff) with `helm plugin install https://github.com/databus23/helm-diff`, then restart helm-browser."},
+	{[]string{"(--validate)"}, "The chart's values do not parse as YAML. Run again without **--validate** to write them as they are."},
+	{[]string{"already exists"}, "Pass **--force** to replace existing files, or choose another **--output-dir**."},
+}
+
+// hintFor returns the hint for an error message, or ""
+func hintFor(text string) string {
+	lower := strings.ToLower(text)
+	for _, h := range errorHints {
+		for _, match := range h.matches {
+			if strings.Contains(lower, strings.ToLower(match)) {
+				return h.hint
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	hintCodeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39"))
+
+	hintBoldStyle = lipgloss.NewStyle().
+			Bold(true)
+
+	hintLinkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("86")).
+			Underline(true)
+
+	// markdownInline matches `code`, **bold** and [text](url)
+	markdownInline = regexp.MustCompile("`([^`]+)`|\\*\\*([^*]+)\\*\\*|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+)
+
+// renderMarkdown renders a hint for the terminal. With colors, code and
+// bold text are styled and links are clickable in terminals that support
+// them; otherwise the markup is dropped and links are written as
+// "text (url)" so the hint still reads well.
+func renderMarkdown(text string, colors bool) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			line = "  • " + item
+		}
+		lines[i] = markdownInline.ReplaceAllStringFunc(line, func(match string) string {
+			parts := markdownInline.FindStringSubmatch(match)
+			switch {
+			case parts[1] != "":
+				if !colors {
+					return parts[1]
+				}
+				return hintCodeStyle.Render(parts[1])
+			case parts[2] != "":
+				if !colors {
+					return parts[2]
+				}
+				return hintBoldStyle.Render(parts[2])
+			default:
+				if !colors || !hyperlinksSupported() {
+					return parts[3] + " (" + parts[4] + ")"
+				}
+				return hyperlink(parts[4], hintLinkStyle.Render(parts[3]))
+			}
+		})
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hyperlinkTerminals are TERM_PROGRAM values of terminals known to render OSC 8 links
+var hyperlinkTerminals = map[string]bool{
+	"iTerm.app": true,
+	"WezTerm":   true,
+	"vscode":    true,
+	"ghostty":   true,
+	"Hyper":     true,
+	"tabby":     true,
+}
+
+// hyperlinksSupported reports whether the terminal is likely to render OSC 8 hyperlinks
+func hyperlinksSupported() bool {
+	switch {
+	case hyperlinkTerminals[os.Getenv("TERM_PROGRAM")]:
+		return true
+	case os.Getenv("WT_SESSION") != "", os.Getenv("KITTY_WINDOW_ID") != "":
+		return true
+	case strings.Contains(os.Getenv("TERM"), "kitty"), strings.Contains(os.Getenv("TERM"), "foot"):
+		return true
+	}
+
+	// GNOME Terminal and other VTE terminals support links since VTE 0.50
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return false
+}
+
+// hyperlink renders text linking to url, or the plain url when the terminal
+// cannot render links
+func hyperlink(url, text string) string {
+	if !hyperlinksSupported() {
+		return url
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleTickMsg checks whether --idle-timeout has passed since the last key
+type idleTickMsg time.Time
+
+// idleTick schedules the next --idle-timeout check after wait
+func idleTick(wait time.Duration) tea.Cmd {
+	return tea.Tick(wait, func(t time.Time) tea.Msg {
+		return idleTickMsg(t)
+	})
+}
+
+// updateIdleTick quits once no key was pressed for --idle-timeout, and
+// otherwise checks again when the timeout would next run out. A download
+// or other command in progress is left to finish first.
+func (m model) updateIdleTick(msg idleTickMsg) (tea.Model, tea.Cmd) {
+	idle := time.Time(msg).Sub(m.lastInput)
+	if idle < m.opts.idleTimeout {
+		return m, idleTick(m.opts.idleTimeout - idle)
+	}
+	if m.loading {
+		return m, idleTick(m.opts.idleTimeout)
+	}
+	return m, tea.Quit
+}
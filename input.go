@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inputKind identifies what a submitted input is used for
+type inputKind int
+
+// Input kinds
+const (
+	inputJumpVersion inputKind = iota
+	inputSearchAll
+	inputPreviewSearch
+	inputDiffRelease
+	inputJumpPage
+	inputSubchart
+	inputOverride
+	inputMinAppVersion
+	inputHubSearch
+	inputAddRepo
+	inputHelmfile
+	inputVersionList
+)
+
+// inputPrompt is a single-line text input shown below the current list
+type inputPrompt struct {
+	active bool
+	kind   inputKind
+	label  string
+	value  string
+	err    string
+}
+
+// openInput starts collecting text for the given purpose
+func (m model) openInput(kind inputKind, label string) model {
+	m.input = inputPrompt{active: true, kind: kind, label: label}
+	return m
+}
+
+// updateInput handles key presses while an input is active
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		if m.input.kind == inputOverride {
+			// Cancelling the overrides cancels the download
+			m.override = overrideSession{}
+			m, _ = m.back()
+		}
+		m.input = inputPrompt{}
+	case tea.KeyEnter:
+		return m.submitInput()
+	case tea.KeyBackspace:
+		if len(m.input.value) > 0 {
+			runes := []rune(m.input.value)
+			m.input.value = string(runes[:len(runes)-1])
+		}
+		m.input.err = ""
+	case tea.KeySpace:
+		m.input.value += " "
+		m.input.err = ""
+	case tea.KeyRunes:
+		m.input.value += string(msg.Runes)
+		m.input.err = ""
+	default:
+		// Other keys are ignored while typing
+	}
+	return m, nil
+}
+
+// submitInput acts on the entered text. Invalid input keeps the prompt open
+// with an inline error.
+func (m model) submitInput() (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(m.input.value)
+
+	switch m.input.kind {
+	case inputJumpVersion:
+		for i, v := range m.versions {
+			if v.Version == value {
+				m.cursor = i
+				m.input = inputPrompt{}
+				return m, nil
+			}
+		}
+		m.input.err = fmt.Sprintf("version %q not found", value)
+
+	case inputSearchAll:
+		if value == "" {
+			m.input.err = "enter a search term"
+			return m, nil
+		}
+		m.input = inputPrompt{}
+		m = m.push()
+		m.searchQuery = value
+		m.cursor = 0
+		m.loading = true
+		m.state = stateChartList
+		return m, searchAllCharts(value, m.opts.exact)
+
+	case inputPreviewSearch:
+		m.input = inputPrompt{}
+		m.preview.search(value)
+
+	case inputJumpPage:
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			m.input.err = "enter a page number"
+			return m, nil
+		}
+		// Out-of-range pages go to the first or last page
+		if total := m.getTotalPages(); page > total {
+			page = total
+		}
+		if page < 1 {
+			page = 1
+		}
+		m.cursor = (page - 1) * m.pageLen()
+		m.input = inputPrompt{}
+
+	case inputSubchart:
+		if value == "" {
+			m.input.err = "enter a subchart name"
+			return m, nil
+		}
+		m.input = inputPrompt{}
+		m = m.push()
+		m.loading = true
+		m.activity = "🧩 Reading subchart values..."
+		m.state = stateDownload
+		return m, downloadSubchartValues(m.repoFor(m.versions[m.selectedVersion].Name), m.versions[m.selectedVersion], value, m.opts)
+
+	case inputOverride:
+		return m.submitOverride(value)
+
+	case inputHubSearch:
+		if value == "" {
+			m.input.err = "enter a search term"
+			return m, nil
+		}
+		m.input = inputPrompt{}
+		m = m.push()
+		m.hubQuery = value
+		m.hubRepos = nil
+		m.hubErr = ""
+		m.activity = ""
+		m.cursor = 0
+		m.loading = true
+		m.state = stateHubSearch
+		return m, searchArtifactHub(value)
+
+	case inputAddRepo:
+		return m.submitAddRepo(value)
+
+	case inputHelmfile:
+		return m.submitHelmfileExport(value)
+
+	case inputVersionList:
+		return m.submitVersionListExport(value)
+
+	case inputMinAppVersion:
+		if value != "" && !parseSemver(value).valid {
+			m.input.err = "enter a semantic version such as 1.25 or 7.2.0"
+			return m, nil
+		}
+		m.input = inputPrompt{}
+		m.minAppVersion = value
+		m = m.applyAppVersionFilter()
+
+	case inputDiffRelease:
+		if value == "" {
+			m.input.err = "enter a release name"
+			return m, nil
+		}
+		m.input = inputPrompt{}
+		m = m.push()
+		m.loading = true
+		m.state = statePreview
+		return m, diffRelease(value, m.versions[m.selectedVersion])
+	}
+
+	return m, nil
+}
+
+// renderInput draws the active input, if any
+func (m model) renderInput() string {
+	if !m.input.active {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString("\n")
+	s.WriteString(selectedStyle.Render(m.input.label+" ") + m.input.value + "█")
+	if m.input.err != "" {
+		s.WriteString("  " + errorStyle.Render(m.input.err))
+	}
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("⌨️  Confirm: Enter • Cancel: Esc"))
+	return s.String()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyHelp documents one key binding for the help line
+type keyHelp struct {
+	desc string
+	keys string
+
+	// detail, when set, fills the %s in desc with the current value
+	detail func(m model) string
+
+	// bound, when set, returns the keys of a binding the user can configure
+	bound func(m model) string
+
+	// when limits the binding to the situations it applies in; nil means always
+	when func(m model) bool
+}
+
+// keyGroup is one help line of related bindings
+type keyGroup struct {
+	icon     string
+	bindings []keyHelp
+}
+
+// Conditions shared by several bindings
+var (
+	canGoBack   = func(m model) bool { return len(m.navStack) > 0 }
+	hasPages    = func(m model) bool { return m.getTotalPages() > 1 }
+	hasGroups   = func(m model) bool { return m.groupRepos }
+	hasSelected = func(m model) bool { return len(m.selected) > 0 }
+	hasErrors   = func(m model) bool { return len(recentErrors.list()) > 0 }
+)
+
+// listNavigation is the first help line of every list
+var listNavigation = keyGroup{"⌨️ ", []keyHelp{
+	{desc: "Navigate", keys: "↑/↓ or j/k"},
+	{desc: "Select", keys: "Enter/Space or number (1-9,0 on the current page)"},
+	{desc: "Go to page", keys: ":", when: hasPages},
+	{desc: "Reload", bound: func(m model) string { return keyLabel(m.reloadKey()) }, when: model.canReload},
+	{desc: "Back", keys: "Backspace/Esc", when: canGoBack},
+	{desc: "Export session", keys: "E", when: func(m model) bool { return len(m.history) > 0 || len(m.selected) > 0 }},
+	{desc: "Recent errors (%s)", keys: "!", when: hasErrors, detail: func(m model) string { return fmt.Sprint(len(recentErrors.list())) }},
+	{desc: "Quit", keys: "q/Ctrl+C"},
+}}
+
+// listKeys are the keys of the list screens. update only dispatches the keys
+// found here, and custom actions cannot take any of them.
+var listKeys = keySet(
+	"ctrl+c", "q", "x", "X", "H", "R", "u", "S", "P", "*", "f", "L", "V", "W", "!", "E",
+	"D", "m", "g", "tab", "T", "F", "v", "p", "y", "d", "c", "/", "a", "o", "i", "t",
+	"G", ":", "s", "A", "shift+up", "shift+down", "z", "Z", "left", "h", "right", "l",
+	"up", "k", "down", "j", "enter", " ", "backspace", "esc",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9",
+)
+
+// keySet turns a list of keys into a set
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// keymap lists the keys handled in each state. Update it together with the
+// key handling so the help line stays accurate.
+var keymap = map[state][]keyGroup{
+	stateRepoList: {
+		listNavigation,
+		{"🔍", []keyHelp{
+			{desc: "Search all repositories", keys: "/"},
+			{desc: "Find on ArtifactHub", keys: "A", when: func(m model) bool { return m.opts.artifactHub }},
+			{desc: "Open URL", keys: "o"},
+			{desc: "Sort (%s)", keys: "s", detail: func(m model) string { return m.repoSort }},
+			{desc: "Columns", keys: "←/→ or h/l", when: func(m model) bool { return m.repoColumns() > 1 }},
+			{desc: "Move", keys: "Shift+↑/↓", when: func(m model) bool { return m.repoSort == sortHelm }},
+			{desc: "Group by section", keys: "G"},
+			{desc: "Collapse/expand section", keys: "z", when: hasGroups},
+			{desc: "Expand all", keys: "Z", when: hasGroups},
+			{desc: "Reachability", keys: "R"},
+			{desc: "Favorite", keys: "*"},
+			{desc: "Favorites first", keys: "f", when: func(m model) bool { return !m.favoritesFirst }},
+			{desc: "Favorites in place", keys: "f", when: func(m model) bool { return m.favoritesFirst }},
+		}},
+	},
+	stateChartList: {
+		listNavigation,
+		{"🏷️ ", []keyHelp{
+			{desc: "Toggle app version column", keys: "a"},
+			{desc: "Open home page", keys: "o"},
+			{desc: "Sort (%s)", keys: "s", detail: func(m model) string { return m.chartSort }},
+			{desc: "Peek at versions", keys: "V"},
+			{desc: "Hide library charts", keys: "L", when: func(m model) bool { return !m.hideLibrary }},
+			{desc: "Show library charts", keys: "L", when: func(m model) bool { return m.hideLibrary }},
+			{desc: "Favorite", keys: "*"},
+			{desc: "Favorites first", keys: "f", when: func(m model) bool { return !m.favoritesFirst }},
+			{desc: "Favorites in place", keys: "f", when: func(m model) bool { return m.favoritesFirst }},
+		}},
+	},
+	stateVersionList: {
+		listNavigation,
+		{"⚡", []keyHelp{
+			{desc: "Actions menu", keys: "Tab"},
+			{desc: "Preview", keys: "v"},
+			{desc: "Pull", keys: "p"},
+			{desc: "Copy reference", keys: "y"},
+			{desc: "Subchart values", keys: "c"},
+			{desc: "Diff release", keys: "d", when: func(m model) bool { return m.actionAvailable(actionDiff) }},
+			{desc: "Details", keys: "i"},
+			{desc: "App version timeline", keys: "t"},
+			{desc: "Minimum app version", keys: "m"},
+			{desc: "Open home page", keys: "o"},
+			{desc: "Jump to an exact version", keys: "g"},
+			{desc: "Open values in a tab", keys: "T"},
+			{desc: "Bundled values files", keys: "F"},
+			{desc: "Back to charts", keys: "V", when: canGoBack},
+		}},
+		{"☑️ ", []keyHelp{
+			{desc: "Select", keys: "x"},
+			{desc: "Download %s selected", keys: "X", when: hasSelected, detail: func(m model) string { return fmt.Sprint(len(m.selected)) }},
+			{desc: "Export to Helmfile", keys: "H", when: hasSelected},
+			{desc: "Export version list", keys: "W"},
+			{desc: "Pin as default", keys: "P", when: func(m model) bool { return m.cursor < len(m.versions) && !m.isPinned(m.versions[m.cursor]) }},
+			{desc: "Unpin", keys: "P", when: func(m model) bool { return m.cursor < len(m.versions) && m.isPinned(m.versions[m.cursor]) }},
+			{desc: "Toggle devel versions", keys: "D"},
+			{desc: "Archive all versions", keys: "A"},
+		}},
+	},
+	statePreview: {
+		{"⌨️ ", []keyHelp{
+			{desc: "Scroll", keys: "↑/↓ or j/k"},
+			{desc: "Page", keys: "PgUp/PgDn or b/f"},
+			{desc: "Page down", keys: "Space"},
+			{desc: "Top/Bottom", keys: "Home/End"},
+			{desc: "Search", keys: "/"},
+			{desc: "Next/previous match", keys: "n/N", when: func(m model) bool { return m.preview.query != "" }},
+			{desc: "Switch tab", keys: "Tab/Shift+Tab", when: func(m model) bool { return m.showingTabs && len(m.tabs) > 1 }},
+			{desc: "Close tab", keys: "x", when: func(m model) bool { return m.showingTabs }},
+			{desc: "Back", keys: "Esc"},
+			{desc: "Quit", keys: "q"},
+		}},
+	},
+	stateHubSearch: {
+		listNavigation,
+		{"➕", []keyHelp{
+			{desc: "Add repository", keys: "Enter"},
+		}},
+	},
+	stateRepoHealth: {
+		listNavigation,
+		{"🩺", []keyHelp{
+			{desc: "Open repository", keys: "Enter"},
+			{desc: "Check again", keys: "R"},
+			{desc: "Show unreachable", keys: "u", when: func(m model) bool { return m.hideUnreachable }},
+			{desc: "Hide unreachable", keys: "u", when: func(m model) bool { return !m.hideUnreachable }},
+			{desc: "Save hide setting", keys: "S"},
+		}},
+	},
+	stateValuesFiles: {
+		listNavigation,
+		{"📄", []keyHelp{
+			{desc: "Download", keys: "Enter"},
+			{desc: "Preview", keys: "v"},
+		}},
+	},
+	stateComplete: {
+		{"⌨️ ", []keyHelp{
+			{desc: "Back", keys: "Backspace/Esc"},
+			{desc: "Copy path", keys: "y", when: func(m model) bool { return len(m.written) > 0 }},
+			{desc: "Exit", keys: "any other key"},
+		}},
+	},
+	stateError: {
+		{"⌨️ ", []keyHelp{
+			{desc: "Back", keys: "Esc", when: canGoBack},
+			{desc: "Recent errors", keys: "!"},
+			{desc: "Quit", keys: "q"},
+		}},
+	},
+}
+
+// helpLines returns the help lines for the keys available right now
+func (m model) helpLines() []string {
+	var lines []string
+	for _, group := range keymap[m.state] {
+		var parts []string
+		for _, binding := range group.bindings {
+			if binding.when != nil && !binding.when(m) {
+				continue
+			}
+			desc := binding.desc
+			if binding.detail != nil {
+				desc = fmt.Sprintf(desc, binding.detail(m))
+			}
+			keys := binding.keys
+			if binding.bound != nil {
+				keys = binding.bound(m)
+			}
+			parts = append(parts, desc+": "+keys)
+		}
+		if len(parts) > 0 {
+			lines = append(lines, group.icon+" "+strings.Join(parts, " • "))
+		}
+	}
+	if m.state == stateVersionList && len(m.cfg.Actions) > 0 {
+		lines = append(lines, m.customActionHelp())
+	}
+	return lines
+}
+
+// keyLabel writes a key name the way the help line does, e.g. ctrl+r as Ctrl+R
+func keyLabel(key string) string {
+	parts := strings.Split(key, "+")
+	if len(parts) == 1 && len(key) == 1 {
+		// A single character is case sensitive: r and R are different keys
+		return key
+	}
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
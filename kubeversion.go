@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// kubeVersionCluster is the --kube-version value that asks the connected
+// cluster for its version
+const kubeVersionCluster = "cluster"
+
+// kubeVersionOption returns the Kubernetes version given with --kube-version,
+// or "" when there is none or it is read from the cluster
+func kubeVersionOption(value string) string {
+	if value == kubeVersionCluster {
+		return ""
+	}
+	return kubeCoreVersion(value)
+}
+
+// clusterVersionMsg carries the Kubernetes version of the connected cluster
+type clusterVersionMsg struct {
+	version string
+	err     error
+}
+
+// loadClusterVersion asks kubectl for the server version of the current context
+func loadClusterVersion() tea.Cmd {
+	return func() tea.Msg {
+		output, err := runCommand(appContext, "kubectl", "version", "-o", "json")
+		if err != nil {
+			return clusterVersionMsg{err: fmt.Errorf("kubectl version failed: %w", err)}
+		}
+
+		var info struct {
+			ServerVersion struct {
+				GitVersion string `json:"gitVersion"`
+			} `json:"serverVersion"`
+		}
+		if err := json.Unmarshal(output, &info); err != nil {
+			return clusterVersionMsg{err: fmt.Errorf("failed to parse kubectl version: %w", err)}
+		}
+		if info.ServerVersion.GitVersion == "" {
+			return clusterVersionMsg{err: fmt.Errorf("kubectl did not report a server version")}
+		}
+		return clusterVersionMsg{version: kubeCoreVersion(info.ServerVersion.GitVersion)}
+	}
+}
+
+// kubeCoreVersion drops the v prefix and any vendor suffix from a Kubernetes
+// version, e.g. v1.28.3-gke.100 becomes 1.28.3
+func kubeCoreVersion(version string) string {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	return version
+}
+
+// kubeVersionAllows reports whether a Kubernetes version satisfies a chart's
+// kubeVersion constraint, e.g. ">= 1.21.0-0 < 1.30.0-0" or "^1.25 || ~1.24".
+// Terms separated by spaces or commas must all hold; || separates alternatives.
+func kubeVersionAllows(constraint, version string) (bool, error) {
+	version = kubeCoreVersion(version)
+	if !parseSemver(version).valid {
+		return false, fmt.Errorf("invalid Kubernetes version %q", version)
+	}
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		terms, err := constraintTerms(alternative)
+		if err != nil {
+			return false, err
+		}
+
+		allowed := true
+		for _, term := range terms {
+			ok, err := matchTerm(term, version)
+			if err != nil {
+				return false, err
+			}
+			allowed = allowed && ok
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// constraintTerms splits one alternative into terms, joining operators
+// written apart from their version and expanding "1.20 - 1.25" ranges
+func constraintTerms(alternative string) ([]string, error) {
+	fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty kubeVersion constraint")
+	}
+
+	var terms []string
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "-" && len(terms) > 0 && i+1 < len(fields):
+			terms[len(terms)-1] = ">=" + terms[len(terms)-1]
+			terms = append(terms, "<="+fields[i+1])
+			i++
+		case strings.Trim(field, "<>=!~^") == "" && i+1 < len(fields):
+			terms = append(terms, field+fields[i+1])
+			i++
+		default:
+			terms = append(terms, field)
+		}
+	}
+	return terms, nil
+}
+
+// partialVersion is the version in a constraint term, where trailing parts
+// may be missing or wildcards: 1.25, 1.x or *
+type partialVersion struct {
+	nums [3]int
+	n    int // number of parts given
+	pre  string
+}
+
+// parsePartialVersion reads the version of a constraint term
+func parsePartialVersion(text string) (partialVersion, error) {
+	var p partialVersion
+	core, pre, _ := strings.Cut(strings.TrimPrefix(text, "v"), "-")
+	p.pre = pre
+	for _, part := range strings.Split(core, ".") {
+		if part == "x" || part == "X" || part == "*" || part == "" {
+			break
+		}
+		if p.n == 3 {
+			return p, fmt.Errorf("invalid version %q in kubeVersion", text)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid version %q in kubeVersion", text)
+		}
+		p.nums[p.n] = n
+		p.n++
+	}
+	return p, nil
+}
+
+// lower returns the lowest version the partial version stands for
+func (p partialVersion) lower() string {
+	v := fmt.Sprintf("%d.%d.%d", p.nums[0], p.nums[1], p.nums[2])
+	if p.pre != "" {
+		v += "-" + p.pre
+	}
+	return v
+}
+
+// bump returns the version after the range of versions that share the
+// first parts+1 numbers, e.g. bump(1) of 1.25.3 is 1.26.0
+func (p partialVersion) bump(part int) string {
+	nums := p.nums
+	nums[part]++
+	for i := part + 1; i < len(nums); i++ {
+		nums[i] = 0
+	}
+	return fmt.Sprintf("%d.%d.%d", nums[0], nums[1], nums[2])
+}
+
+// matchTerm checks a version against a single term such as >=1.21.0-0
+func matchTerm(term, version string) (bool, error) {
+	op := term[:len(term)-len(strings.TrimLeft(term, "<>=!~^"))]
+	p, err := parsePartialVersion(term[len(op):])
+	if err != nil {
+		return false, err
+	}
+
+	atLeast := func(bound string) bool { return compareVersions(version, bound) >= 0 }
+	below := func(bound string) bool { return compareVersions(version, bound) < 0 }
+
+	// Wildcard parts: 1.25 and 1.25.x both mean any 1.25 patch release
+	inRange := p.n == 0 || atLeast(p.lower()) && below(p.bump(p.n-1))
+	if p.n == 3 {
+		inRange = compareVersions(version, p.lower()) == 0
+	}
+
+	switch op {
+	case "", "=", "==":
+		return inRange, nil
+	case "!=":
+		return !inRange, nil
+	case ">":
+		return p.n > 0 && !inRange && atLeast(p.lower()), nil
+	case ">=", "=>":
+		return atLeast(p.lower()), nil
+	case "<":
+		return below(p.lower()), nil
+	case "<=", "=<":
+		return p.n == 0 || below(p.lower()) || inRange, nil
+	case "~", "~>":
+		if p.n == 0 {
+			return true, nil
+		}
+		part := 1
+		if p.n == 1 {
+			part = 0
+		}
+		return atLeast(p.lower()) && below(p.bump(part)), nil
+	case "^":
+		if p.n == 0 {
+			return true, nil
+		}
+		// The first non-zero part given may not change
+		part := p.n - 1
+		for i := 0; i < p.n; i++ {
+			if p.nums[i] != 0 {
+				part = i
+				break
+			}
+		}
+		return atLeast(p.lower()) && below(p.bump(part)), nil
+	}
+	return false, fmt.Errorf("unsupported operator %q in kubeVersion", op)
+}
+
+// kubeCompatibility describes a chart version's Kubernetes requirement and,
+// when the cluster version is known, whether the cluster meets it. The
+// second result reports an incompatibility.
+func (m model) kubeCompatibility(metadata *chartMetadata) (string, bool) {
+	if metadata.KubeVersion == "" {
+		return "Kubernetes: any version (no kubeVersion constraint)", false
+	}
+
+	line := "Kubernetes: " + metadata.KubeVersion
+	if m.kubeVersion == "" {
+		return line, false
+	}
+
+	ok, err := kubeVersionAllows(metadata.KubeVersion, m.kubeVersion)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s • cannot check against %s: %v", line, m.kubeVersion, err), false
+	case ok:
+		return fmt.Sprintf("%s • ✅ %s is supported", line, m.kubeVersion), false
+	}
+	return fmt.Sprintf("%s • ⚠️  %s is not supported", line, m.kubeVersion), true
+}
+
+// kubeVersionBanner warns when the highlighted version does not support the
+// Kubernetes version being checked
+func (m model) kubeVersionBanner() string {
+	if m.kubeVersion == "" || m.cursor >= len(m.versions) {
+		return ""
+	}
+
+	version := m.versions[m.cursor]
+	metadata := m.details[downloadKey(version.Name, version.Version)]
+	if metadata == nil || metadata.err != nil {
+		return ""
+	}
+	if _, incompatible := m.kubeCompatibility(metadata); !incompatible {
+		return ""
+	}
+	return errorStyle.Render(fmt.Sprintf("⚠️  %s %s requires Kubernetes %s, which excludes %s",
+		version.Name, version.Version, metadata.KubeVersion, m.kubeVersion))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listUsage describes the list subcommand
+const listUsage = `Usage:
+  helm-browser list repos [--json]
+  helm-browser list charts <repo> [--json]
+  helm-browser list versions <repo/chart> [--json]
+`
+
+// runList prints repositories, charts or versions without the TUI and
+// returns the exit code: 0 on success, 1 when helm fails, 2 on bad usage
+func runList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("helm-browser list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { _, _ = fmt.Fprint(stderr, listUsage) }
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+
+	// Allow flags before, between and after the positional arguments
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var (
+		data  any
+		table [][]string
+		err   error
+	)
+
+	switch {
+	case positional[0] == "repos" && len(positional) == 1:
+		var repos []HelmRepo
+		repos, err = runRepos()
+		if repos == nil {
+			repos = []HelmRepo{}
+		}
+		data = repos
+		table = append(table, []string{"NAME", "URL"})
+		for _, r := range repos {
+			table = append(table, []string{r.Name, r.URL})
+		}
+
+	case positional[0] == "charts" && len(positional) == 2:
+		var charts []HelmChart
+		charts, err = runCharts(loadCharts(positional[1]))
+		data = charts
+		table = append(table, []string{"NAME", "VERSION", "APP VERSION", "DESCRIPTION"})
+		for _, c := range charts {
+			table = append(table, []string{c.Name, c.Version, c.AppVersion, c.Description})
+		}
+
+	case positional[0] == "versions" && len(positional) == 2:
+		var versions []HelmVersion
+		versions, err = runVersions(positional[1], false)
+		data = versions
+		table = append(table, []string{"NAME", "VERSION", "APP VERSION"})
+		for _, v := range versions {
+			table = append(table, []string{v.Name, v.Version, v.AppVersion})
+		}
+
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, row := range table {
+		for i, cell := range row {
+			if i > 0 {
+				_, _ = fmt.Fprint(tw, "\t")
+			}
+			_, _ = fmt.Fprint(tw, cell)
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runCharts runs a chart loading command synchronously
+func runCharts(cmd tea.Cmd) ([]HelmChart, error) {
+	switch msg := cmd().(type) {
+	case chartsLoadedMsg:
+		if msg == nil {
+			return []HelmChart{}, nil
+		}
+		return msg, nil
+	case errorMsg:
+		return nil, fmt.Errorf("%s", msg)
+	default:
+		return nil, fmt.Errorf("unexpected result %T", msg)
+	}
+}
+
+// runVersions loads the versions of exactly one chart synchronously
+func runVersions(chartName string, devel bool) ([]HelmVersion, error) {
+	switch msg := loadVersions(chartName, devel)().(type) {
+	case versionsLoadedMsg:
+		versions := []HelmVersion{}
+		for _, v := range msg {
+			if v.Name == chartName {
+				versions = append(versions, v)
+			}
+		}
+		return versions, nil
+	case errorMsg:
+		return nil, fmt.Errorf("%s", msg)
+	default:
+		return nil, fmt.Errorf("unexpected result %T", msg)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logEntry is one line of the --log-file, written as JSON
+type logEntry struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Command    []string  `json:"command,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+}
+
+// sessionLogger appends log entries to a file. Background commands run
+// concurrently, so writes are serialised with a mutex.
+type sessionLogger struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// sessionLog is the logger for --log-file, nil when logging is off
+var sessionLog *sessionLogger
+
+// openSessionLog starts logging to path, appending to an existing file
+func openSessionLog(path string) (*sessionLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &sessionLogger{file: file, enc: json.NewEncoder(file)}
+	l.log(logEntry{Event: "session start", Command: os.Args, OK: true})
+	return l, nil
+}
+
+// log writes one entry; a nil logger discards it
+func (l *sessionLogger) log(entry logEntry) {
+	if l == nil {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(entry)
+}
+
+// close ends the session log
+func (l *sessionLogger) close() {
+	if l == nil {
+		return
+	}
+	l.log(logEntry{Event: "session end", OK: true})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.file.Close()
+}
+
+// logRun runs a helm command through run (e.g. cmd.Output) and records the
+// command, how long it took and how it ended in the session log
+func logRun(cmd *exec.Cmd, run func() ([]byte, error)) ([]byte, error) {
+	start := time.Now()
+	output, err := run()
+
+	entry := logEntry{
+		Time:       start,
+		Event:      "helm",
+		Command:    cmd.Args,
+		DurationMS: time.Since(start).Milliseconds(),
+		OK:         err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			entry.Stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		// With CombinedOutput the error text is in the output instead
+		if entry.Stderr == "" {
+			entry.Stderr = strings.TrimSpace(string(output))
+		}
+		recentErrors.add(true, strings.TrimSpace(fmt.Sprintf("%s: %s\n%s", strings.Join(cmd.Args, " "), entry.Error, entry.Stderr)))
+	}
+	sessionLog.log(entry)
+	return output, err
+}
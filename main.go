@@ -4,15 +4,22 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Styles
@@ -45,6 +52,19 @@ var (
 	latestBadgeStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("46")).
 		Bold(true)
+
+	downloadedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("42"))
+
+	groupHeaderStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	develBadgeStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("208"))
+
+	skeletonStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("237"))
 )
 
 // the state represents the current state of the application
@@ -59,15 +79,28 @@ const (
 	stateDownload
 	stateError
 	stateComplete
+	statePreview
+	stateHubSearch
+	stateValuesFiles
+	stateRepoHealth
 )
 
 // pageSize defines the number of items to show per page
 const pageSize = 10
 
+// Smallest terminal the list layout fits in
+const (
+	minWidth  = 60
+	minHeight = 18
+)
+
 // HelmRepo represents a Helm repository with name and URL
 type HelmRepo struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+
+	// section is set on the row standing for a collapsed section
+	section string
 }
 
 // HelmChart represents a Helm chart with metadata
@@ -76,6 +109,9 @@ type HelmChart struct {
 	Version     string `json:"version"`
 	AppVersion  string `json:"app_version"`
 	Description string `json:"description"`
+
+	// Extra holds fields of helm's search output not listed above
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // HelmVersion represents a specific version of a Helm chart
@@ -89,6 +125,7 @@ type HelmVersion struct {
 // the model represents the application state for the Helm browser TUI
 type model struct {
 	state           state
+	allRepos        []HelmRepo
 	repos           []HelmRepo
 	charts          []HelmChart
 	versions        []HelmVersion
@@ -99,93 +136,435 @@ type model struct {
 	loading         bool
 	error           string
 	message         string
+	opts            options
+	cfg             config
+
+	// downloaded records the chart versions fetched during this session
+	downloaded map[string]bool
+
+	// input is the text prompt currently collecting keystrokes, if any
+	input inputPrompt
+
+	// Repository grouping: sections come from the config file
+	groupRepos bool
+	collapsed  map[string]bool
+
+	// repoSort is the repository order: helm's, by name or by URL host
+	repoSort string
+
+	// Terminal size, reported by Bubble Tea
+	width  int
+	height int
+
+	// Version actions: the values preview, the Tab action menu and the
+	// one-line status shown after an action such as copying
+	preview    viewport
+	menuOpen   bool
+	menuCursor int
+	activity   string
+	status     string
+
+	// chartCache holds chart lists already loaded this session, by repository
+	chartCache    map[string][]HelmChart
+	prefetching   bool
+	prefetchDone  int
+	prefetchTotal int
+
+	// appVersionColumn shows each chart's app version instead of its chart
+	// version in the chart list
+	appVersionColumn bool
+
+	// Chart details panel, with metadata cached by chart version
+	showDetails bool
+	details     map[string]*chartMetadata
+
+	// showTimeline shows how the app version changed across chart versions
+	showTimeline bool
+
+	// searchQuery is set while the chart list shows results from all repositories
+	searchQuery string
+
+	// navStack holds the screens esc returns to, most recent last
+	navStack []navFrame
+
+	// devel includes development (prerelease) versions in the version list
+	devel bool
+
+	// latest is the highest version in the version list, worked out once per load
+	latest string
+
+	// override holds the answers being collected with --override
+	override overrideSession
+
+	// stats feeds the summary printed on exit
+	stats sessionStats
+
+	// lastInput is when a key was last pressed, for --idle-timeout
+	lastInput time.Time
+
+	// written lists the files shown on the complete screen
+	written []string
+
+	// allVersions is the loaded version list before the minimum app version
+	// filter; nonSemverApps counts the versions it left out as not comparable
+	allVersions   []HelmVersion
+	minAppVersion string
+	nonSemverApps int
+
+	// With --watch, versions that appeared since the list was opened, by
+	// download key, and when the list was last refreshed
+	newVersions map[string]bool
+	lastWatch   time.Time
+	watching    bool
+
+	// kubeVersion is the Kubernetes version chart versions are checked
+	// against, from --kube-version or the connected cluster
+	kubeVersion string
+
+	// ArtifactHub search: the repositories found for hubQuery, or why the
+	// search failed
+	hubRepos []hubRepo
+	hubQuery string
+	hubErr   string
+
+	// target is the repo or repo/chart from the command line still to be opened
+	target string
+
+	// plugins lists the installed helm plugins, nil until checked
+	plugins map[string]bool
+
+	// selected holds the versions marked for a batch download, by download
+	// key. confirmQuit is set while asking whether to quit without them.
+	selected    map[string]HelmVersion
+	confirmQuit bool
+
+	// Archiving every version's values: confirmingArchive is set while
+	// asking, archiveDone counts the versions fetched so far
+	confirmingArchive bool
+	archiveDone       int
+	archiveTotal      int
+
+	// Tabbed preview: the chart versions whose values are open in tabs and
+	// the one shown. showingTabs is set while the preview shows the tabs.
+	tabs        []previewTab
+	activeTab   int
+	showingTabs bool
+
+	// valuesFiles are the values files bundled with the selected version
+	valuesFiles []valuesFile
+
+	// reload remembers the highlighted item while the list is reloaded
+	reload reloadTarget
+
+	// listOffset is the first row in view of a --no-paging list
+	listOffset int
+
+	// rows caches rendered list rows between View calls
+	rows rowCache
+
+	// history records completed downloads and pulls for the session export
+	history []sessionStep
+
+	// startup shows how far the repository update and load got
+	startup startupProgress
+
+	// helmEnv holds helm's paths, read with helm env at startup
+	helmEnv helmEnv
+
+	// peek is the version list last left with V
+	peek versionPeek
+
+	// Repository reachability: the results of the last check, sorted
+	// unreachable first, and whether unreachable repositories are hidden
+	health          []repoHealth
+	unreachable     map[string]bool
+	hideUnreachable bool
+	checking        bool
+	checked         int
+
+	// Chart order; version counts are looked up lazily and cached by chart
+	chartSort     string
+	versionCounts map[string]int
+	counting      bool
+
+	// Chart types are read lazily once library charts are hidden
+	chartTypes    map[string]string
+	hideLibrary   bool
+	checkingTypes bool
+
+	// favoritesFirst floats the favorite repositories and charts to the top
+	favoritesFirst bool
+
+	// hubVersions are ArtifactHub's versions of a chart listed with one
+	hubVersions hubVersionsMsg
 }
 
 // initialModel creates a new model with default values
-func initialModel() model {
+func initialModel(opts options, cfg config) model {
 	return model{
-		state:   stateRepoUpdate,
-		loading: true,
+		state:           stateRepoUpdate,
+		loading:         true,
+		opts:            opts,
+		cfg:             cfg,
+		downloaded:      make(map[string]bool),
+		chartCache:      make(map[string][]HelmChart),
+		details:         make(map[string]*chartMetadata),
+		groupRepos:      opts.groupRepos,
+		collapsed:       make(map[string]bool),
+		selected:        make(map[string]HelmVersion),
+		rows:            make(rowCache),
+		chartSort:       opts.sortCharts,
+		versionCounts:   make(map[string]int),
+		chartTypes:      make(map[string]string),
+		repoSort:        opts.sortRepos,
+		target:          opts.target,
+		devel:           opts.devel,
+		stats:           sessionStats{started: time.Now()},
+		lastInput:       time.Now(),
+		newVersions:     make(map[string]bool),
+		kubeVersion:     kubeVersionOption(opts.kubeVersion),
+		hideUnreachable: cfg.HideUnreachable,
+		favoritesFirst:  cfg.FavoritesFirst,
+	}
+}
+
+// downloadKey identifies a chart version in the downloaded set
+func downloadKey(chartName, version string) string {
+	return chartName + "@" + version
+}
+
+// chartDownloaded reports whether any version of the chart was downloaded this session
+func (m model) chartDownloaded(chartName string) bool {
+	prefix := downloadKey(chartName, "")
+	for key := range m.downloaded {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
 	}
+	return false
 }
 
 // Init satisfies the tea.Model interface
 func (m model) Init() tea.Cmd {
-	return updateRepos()
+	cmds := []tea.Cmd{updateRepos(m.opts.updateRepos, m.opts.updateBestEffort), loadPlugins(), loadHelmEnv()}
+	if m.opts.watch > 0 {
+		cmds = append(cmds, watchTick(m.opts.watch))
+	}
+	if m.opts.kubeVersion == kubeVersionCluster {
+		cmds = append(cmds, loadClusterVersion())
+	}
+	if m.opts.idleTimeout > 0 {
+		cmds = append(cmds, idleTick(m.opts.idleTimeout))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Helper functions for pagination
 
-// getCurrentPage returns the current page number (0-indexed)
+// getCurrentPage returns the current page number (0-indexed). A --no-paging
+// list is a single page.
 func (m model) getCurrentPage() int {
-	return m.cursor / pageSize
+	if m.opts.noPaging {
+		return 0
+	}
+	return m.cursor / m.pageLen()
 }
 
-// getPageStart returns the starting index for the current page
+// getPageStart returns the starting index for the current page, or the
+// first row in view with --no-paging
 func (m model) getPageStart() int {
-	return m.getCurrentPage() * pageSize
+	if m.opts.noPaging {
+		return m.listOffset
+	}
+	return m.getCurrentPage() * m.pageLen()
 }
 
 // getPageEnd returns the ending index for the current page
 func (m model) getPageEnd(totalItems int) int {
-	end := m.getPageStart() + pageSize
+	end := m.getPageStart() + m.pageLen()
 	if end > totalItems {
 		end = totalItems
 	}
 	return end
 }
 
+// shortcutPosition maps a number key to a position on the current page:
+// 1-9 select the first nine items and 0 the tenth
+func shortcutPosition(key string) (int, bool) {
+	num, err := strconv.Atoi(key)
+	if err != nil || len(key) != 1 {
+		return 0, false
+	}
+	if num == 0 {
+		return pageSize - 1, true
+	}
+	return num - 1, true
+}
+
+// pageNumber returns the label of the item at index i: its position on
+// its page, which is also the number key that selects it
+func pageNumber(i int) string {
+	return fmt.Sprintf("%d.", i%pageSize+1)
+}
+
 // getCursorInPage returns the cursor position within the current page
 func (m model) getCursorInPage() int {
-	return m.cursor % pageSize
+	return m.cursor - m.getPageStart()
+}
+
+// tooSmall reports whether the terminal is below the minimum size. The size
+// is unknown (zero) until the first WindowSizeMsg arrives.
+func (m model) tooSmall() bool {
+	return m.width > 0 && m.height > 0 && (m.width < minWidth || m.height < minHeight)
+}
+
+// getItemCount returns the number of items in the current list
+func (m model) getItemCount() int {
+	switch m.state {
+	case stateRepoList:
+		return len(m.repos)
+	case stateChartList:
+		return len(m.charts)
+	case stateVersionList:
+		return len(m.versions)
+	case stateHubSearch:
+		return len(m.hubRepos)
+	case stateValuesFiles:
+		return len(m.valuesFiles)
+	case stateRepoHealth:
+		return len(m.health)
+	default:
+		return 0
+	}
+}
+
+// getTotalPages returns the number of pages in the current list
+func (m model) getTotalPages() int {
+	if m.opts.noPaging {
+		return 1
+	}
+	return (m.getItemCount() + m.pageLen() - 1) / m.pageLen()
 }
 
 // Message types for Bubble Tea communication
-type repoUpdateMsg struct{}
+type repoUpdateMsg struct {
+	warning string
+	failed  bool // the update failed and cached data is used
+}
 type reposLoadedMsg []HelmRepo
 type chartsLoadedMsg []HelmChart
 type versionsLoadedMsg []HelmVersion
-type downloadCompleteMsg string
+type downloadCompleteMsg struct {
+	version HelmVersion
+	path    string
+	empty   bool   // the chart ships an empty default values.yaml
+	warning string // e.g. the values were written as YAML instead of JSON
+}
 type errorMsg string
 
 // Bubble Tea commands for async operations
 
-// updateRepos runs the helm repo update command
-func updateRepos() tea.Cmd {
+// updateRepos runs the helm repo update command, limited to the named
+// repositories when any are given. With bestEffort a failed update is only
+// a warning and the cached repository data is used.
+func updateRepos(names []string, bestEffort bool) tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command("helm", "repo", "update")
-		if err := cmd.Run(); err != nil {
-			return errorMsg(fmt.Sprintf("Failed to update repos: %v", err))
+		args := []string{"repo", "update"}
+		var warning string
+
+		if len(names) > 0 {
+			known, unknown, err := splitKnownRepos(names)
+			if err != nil {
+				return errorMsg(fmt.Sprintf("Failed to list repos: %v", err))
+			}
+			if len(unknown) > 0 {
+				warning = fmt.Sprintf("⚠️  Not updated, unknown repositories: %s", strings.Join(unknown, ", "))
+			}
+			if len(known) == 0 {
+				return repoUpdateMsg{warning: warning}
+			}
+			args = append(append(args, "--"), known...)
+		}
+
+		if _, err := runHelm(appContext, args...); err != nil {
+			if !bestEffort {
+				return errorMsg(fmt.Sprintf("Failed to update repos: %v", err))
+			}
+			reason := err.Error()
+			if lines := strings.Split(commandStderr(err), "\n"); lines[len(lines)-1] != "" {
+				reason = lines[len(lines)-1]
+			}
+			warning = strings.TrimSpace(warning + "\n" + fmt.Sprintf("⚠️  Repository update failed, showing cached data: %s", reason))
+			return repoUpdateMsg{warning: warning, failed: true}
+		}
+		return repoUpdateMsg{warning: warning}
+	}
+}
+
+// splitKnownRepos separates the names of configured repositories from unknown ones
+func splitKnownRepos(names []string) (known, unknown []string, err error) {
+	repos, err := runRepos()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configured := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		configured[repo.Name] = true
+	}
+
+	for _, name := range names {
+		if configured[name] {
+			known = append(known, name)
+		} else {
+			unknown = append(unknown, name)
 		}
-		return repoUpdateMsg{}
 	}
+	return known, unknown, nil
 }
 
 // loadRepos fetches the list of configured Helm repositories
 func loadRepos() tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command("helm", "repo", "list", "-o", "json")
-		output, err := cmd.Output()
+		output, err := runHelm(appContext, "repo", "list", "-o", "json")
 		if err != nil {
 			return errorMsg(fmt.Sprintf("Failed to list repos: %v", err))
 		}
 
-		var repos []HelmRepo
-		if len(output) > 0 {
-			if err := json.Unmarshal(output, &repos); err != nil {
-				return errorMsg(fmt.Sprintf("Failed to parse repos: %v", err))
-			}
+		repos, err := parseRepos(output)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to parse repos: %v", err))
 		}
 
 		return reposLoadedMsg(repos)
 	}
 }
 
+// parseRepos decodes the output of helm repo list. Some helm versions emit
+// null, an empty object or nothing at all when no repositories are configured,
+// so anything that isn't a JSON array is treated as "no repositories".
+func parseRepos(output []byte) ([]HelmRepo, error) {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		if len(trimmed) > 0 && !json.Valid(trimmed) {
+			return nil, fmt.Errorf("unexpected output: %q", trimmed)
+		}
+		return nil, nil
+	}
+
+	var repos []HelmRepo
+	if err := json.Unmarshal(trimmed, &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
 // loadCharts fetches charts from a specific repository
 func loadCharts(repoName string) tea.Cmd {
+	repoName = normalizeRepoName(repoName)
 	return func() tea.Msg {
-		cmd := exec.Command("helm", "search", "repo", repoName+"/", "-o", "json")
-		output, err := cmd.Output()
+		// "--" keeps a repository name starting with "-" from being read as a flag
+		output, err := runHelm(appContext, "search", "repo", "-o", "json", "--", repoName+"/")
 		if err != nil {
 			return errorMsg(fmt.Sprintf("Failed to search charts: %v", err))
 		}
@@ -197,15 +576,22 @@ func loadCharts(repoName string) tea.Cmd {
 			}
 		}
 
-		return chartsLoadedMsg(charts)
+		return chartsLoadedMsg(filterRepoCharts(charts, repoName))
 	}
 }
 
-// loadVersions fetches all versions of a specific chart
-func loadVersions(chartName string) tea.Cmd {
+// loadVersions fetches all versions of a specific chart. With devel set,
+// development versions hidden by default are included as well.
+func loadVersions(chartName string, devel bool) tea.Cmd {
+	if isOCIRef(chartName) {
+		return loadOCITags(chartName, devel)
+	}
 	return func() tea.Msg {
-		cmd := exec.Command("helm", "search", "repo", chartName, "--versions", "-o", "json")
-		output, err := cmd.Output()
+		args := []string{"search", "repo", "--versions", "-o", "json"}
+		if devel {
+			args = append(args, "--devel")
+		}
+		output, err := runHelm(appContext, append(args, "--", chartName)...)
 		if err != nil {
 			return errorMsg(fmt.Sprintf("Failed to search versions: %v", err))
 		}
@@ -221,37 +607,369 @@ func loadVersions(chartName string) tea.Cmd {
 	}
 }
 
-// downloadValues downloads the default values.yaml for a chart version
-func downloadValues(chartName, version string) tea.Cmd {
+// downloadValues downloads the default values.yaml for a chart version, or
+// the whole helm show all output with --show all
+func downloadValues(repo HelmRepo, chart HelmVersion, opts options) tea.Cmd {
 	return func() tea.Msg {
-		// Get values using helm show values
-		cmd := exec.Command("helm", "show", "values", chartName, "--version", version)
-		values, err := cmd.Output()
-		if err != nil {
-			return errorMsg(fmt.Sprintf("Failed to get chart values: %v", err))
-		}
+		return fetchAndSaveValues(repo, chart, opts, nil)
+	}
+}
+
+// downloadValuesProgress is downloadValues showing the progress of writing
+// and hashing large values
+func downloadValuesProgress(repo HelmRepo, chart HelmVersion, opts options) tea.Cmd {
+	return withProgress(func(report progressFunc) tea.Msg {
+		return fetchAndSaveValues(repo, chart, opts, report)
+	})
+}
 
-		// Create filename
-		chartParts := strings.Split(chartName, "/")
-		chartBaseName := chartParts[len(chartParts)-1]
-		filename := fmt.Sprintf("%s-%s-default-values.yaml", chartBaseName, version)
+// fetchAndSaveValues gets the values of a chart version and writes them
+func fetchAndSaveValues(repo HelmRepo, chart HelmVersion, opts options, report progressFunc) tea.Msg {
+	chartName, version := chart.Name, chart.Version
 
-		// Write to file
-		if err := os.WriteFile(filename, values, 0644); err != nil {
-			return errorMsg(fmt.Sprintf("Failed to write values file: %v", err))
-		}
+	var values []byte
+	var err error
+	if opts.show == showAll {
+		values, err = runHelm(appContext, "show", showAll, "--version", version, "--", chartName)
+	} else {
+		values, err = fetchValues(chartName, version)
+	}
+	if err != nil {
+		return errorMsg(fmt.Sprintf("Failed to get chart values: %v", err))
+	}
+
+	return saveValues(repo, chart, values, opts, report)
+}
+
+// writeValues applies the output options to the values of a chart version
+// and writes them in the background, showing the progress for large values
+func writeValues(repo HelmRepo, chart HelmVersion, values []byte, opts options) tea.Cmd {
+	return withProgress(func(report progressFunc) tea.Msg {
+		return saveValues(repo, chart, values, opts, report)
+	})
+}
+
+// saveValues applies the output options to the values of a chart version
+// and writes them, with the provenance sidecar when requested
+func saveValues(repo HelmRepo, chart HelmVersion, values []byte, opts options, report progressFunc) tea.Msg {
+	if err := checkValues(values, opts); err != nil {
+		return errorMsg(fmt.Sprintf("Refusing to write %s %s: %v", chart.Name, chart.Version, err))
+	}
+
+	var warning string
+	transformed, err := transformValues(values, opts)
+	switch {
+	case opts.show == showAll:
+		// The full dump is written as helm printed it
+	case err != nil && opts.format == formatJSON:
+		// Content JSON cannot express is kept as the original YAML
+		warning = fmt.Sprintf("⚠️  Could not convert values to JSON (%v), wrote YAML instead", err)
+		opts.format = formatYAML
+		opts.stripComments = false
+	case err != nil:
+		return errorMsg(fmt.Sprintf("Failed to process chart values: %v", err))
+	default:
+		values = transformed
+	}
+	empty := len(bytes.TrimSpace(values)) == 0
+	values = withSourceHeader(values, chart.Name+" "+chart.Version, repo, opts)
 
-		return downloadCompleteMsg(filename)
+	// Create filename
+	filename, err := valuesPath(repo, chart, opts)
+	if err != nil {
+		return errorMsg(fmt.Sprintf("Failed to build values filename: %v", err))
 	}
+
+	// Write to file
+	if err := writeValuesFileProgress(filename, values, opts, report); err != nil {
+		return errorMsg(fmt.Sprintf("Failed to write values file: %v", err))
+	}
+
+	if opts.writeProvenance {
+		if err := writeProvenance(filename, repo, chart, values, opts, report); err != nil {
+			return errorMsg(fmt.Sprintf("Failed to write provenance file: %v", err))
+		}
+	}
+
+	return downloadCompleteMsg{version: chart, path: filename, empty: empty, warning: warning}
 }
 
 // Update handles incoming messages and updates the model state
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Any key counts as activity for --idle-timeout
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.lastInput = time.Now()
+	}
+	next, cmd := m.update(msg)
+
+	// Keep the details panel in step with the cursor, wherever it moved
+	if nm, ok := next.(model); ok {
+		nm = nm.scrollList()
+		nm, detailsCmd := nm.withDetails()
+		return nm, tea.Batch(cmd, detailsCmd)
+	}
+	return next, cmd
+}
+
+// update applies a single message to the model
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		m.status = ""
+
+		if m.confirmQuit {
+			return m.updateConfirmQuit(msg)
+		}
+		if m.confirmingArchive {
+			return m.updateConfirmArchive(msg)
+		}
+		if m.input.active {
+			return m.updateInput(msg)
+		}
+		if m.menuOpen {
+			return m.updateMenu(msg)
+		}
+		if m.state == statePreview && !m.loading {
+			return m.updatePreview(msg)
+		}
+
+		// Any key exits the complete screen, except back which keeps browsing
+		// and y which copies the written paths
+		if m.state == stateComplete {
+			switch msg.String() {
+			case "backspace", "esc":
+				prev, _ := m.back()
+				return prev, nil
+			case "y":
+				if len(m.written) > 0 {
+					return m, copyText(absolutePaths(m.written))
+				}
+				return m.quit()
+			default:
+				return m.quit()
+			}
+		}
+
+		if msg.String() == m.reloadKey() && m.canReload() {
+			return m.reloadList()
+		}
+		if action, ok := m.customActionKey(msg.String()); ok && m.state == stateVersionList && !m.loading {
+			return m.startCustomAction(action)
+		}
+
+		// Keys missing from listKeys fall through to the number shortcuts
+		listKey := msg.String()
+		if !listKeys[listKey] {
+			listKey = ""
+		}
+		switch listKey {
 		case "ctrl+c", "q":
-			return m, tea.Quit
+			return m.quit()
+
+		case "x":
+			if m.state == stateVersionList && !m.loading {
+				m = m.toggleSelected()
+			}
+
+		case "X":
+			if m.state == stateVersionList && !m.loading {
+				return m.downloadSelected()
+			}
+
+		case "H":
+			if m.state == stateVersionList && !m.loading {
+				return m.openHelmfileExport(), nil
+			}
+
+		case "R":
+			switch {
+			case m.state == stateRepoList && !m.loading:
+				return m.openHealth()
+			case m.state == stateRepoHealth:
+				m.health = nil
+				m.cursor = 0
+				m, cmd := m.startHealthCheck()
+				return m, cmd
+			}
+
+		case "u":
+			if m.state == stateRepoHealth && !m.checking {
+				return m.toggleHideUnreachable(), nil
+			}
+
+		case "S":
+			if m.state == stateRepoHealth {
+				m, cmd := m.saveHideUnreachable()
+				return m, cmd
+			}
+
+		case "P":
+			if m.state == stateVersionList && !m.loading {
+				m, cmd := m.togglePin()
+				return m, cmd
+			}
+
+		case "*":
+			if (m.state == stateRepoList || m.state == stateChartList) && !m.loading {
+				return m.toggleFavorite()
+			}
+
+		case "f":
+			if (m.state == stateRepoList || m.state == stateChartList) && !m.loading {
+				return m.toggleFavoritesFirst()
+			}
+
+		case "L":
+			if m.state == stateChartList && !m.loading {
+				return m.toggleLibraryCharts()
+			}
+
+		case "V":
+			if (m.state == stateChartList || m.state == stateVersionList) && !m.loading {
+				return m.toggleVersions()
+			}
+
+		case "W":
+			if m.state == stateVersionList && !m.loading {
+				return m.openVersionListExport(), nil
+			}
+
+		case "!":
+			switch m.state {
+			case stateRepoList, stateChartList, stateVersionList, stateHubSearch, stateValuesFiles, stateRepoHealth, stateError:
+				return m.openRecentErrors(), nil
+			}
+
+		case "E":
+			if !m.loading && (m.state == stateRepoList || m.state == stateChartList || m.state == stateVersionList) {
+				m = m.exportSession()
+			}
+
+		case "D":
+			if m.state == stateVersionList && !m.loading {
+				m.devel = !m.devel
+				m.loading = true
+				return m, loadVersions(m.charts[m.selectedChart].Name, m.devel)
+			}
+
+		case "m":
+			if m.state == stateVersionList && !m.loading {
+				return m.openInput(inputMinAppVersion, "🎯 Minimum app version (empty to clear):"), nil
+			}
+
+		case "g":
+			if m.state == stateVersionList && !m.loading && len(m.versions) > 0 {
+				return m.openInput(inputJumpVersion, "🎯 Jump to version:"), nil
+			}
+
+		case "tab":
+			if m.state == stateVersionList && !m.loading && len(m.versions) > 0 {
+				m.menuOpen = true
+				m.menuCursor = 0
+			}
+
+		case "T":
+			if m.state == stateVersionList && !m.loading {
+				return m.openTab()
+			}
+
+		case "F":
+			if m.state == stateVersionList && !m.loading {
+				return m.openValuesFiles()
+			}
+
+		case "v", "p", "y", "d", "c":
+			if m.state == stateValuesFiles && msg.String() == "v" && !m.loading {
+				return m.previewValuesFile()
+			}
+			if m.state == stateVersionList && !m.loading {
+				for _, item := range menuActions {
+					if item.key == msg.String() {
+						return m.runAction(item.action)
+					}
+				}
+			}
+
+		case "/":
+			if m.state == stateRepoList && !m.loading {
+				return m.openInput(inputSearchAll, "🔍 Search all repositories:"), nil
+			}
+
+		case "a":
+			if m.state == stateChartList {
+				m.appVersionColumn = !m.appVersionColumn
+			}
+
+		case "o":
+			if !m.loading {
+				return m.openHighlighted()
+			}
+
+		case "i":
+			if m.state == stateVersionList {
+				m.showDetails = !m.showDetails
+			}
+
+		case "t":
+			if m.state == stateVersionList {
+				m.showTimeline = !m.showTimeline
+			}
+
+		case "G":
+			if m.state == stateRepoList {
+				m.groupRepos = !m.groupRepos
+				m = m.applyRepoView()
+			}
+
+		case ":":
+			if m.getTotalPages() > 1 && !m.loading {
+				return m.openInput(inputJumpPage, fmt.Sprintf("📄 Go to page (1-%d):", m.getTotalPages())), nil
+			}
+
+		case "s":
+			if m.state == stateRepoList && !m.loading {
+				m = m.nextRepoSort()
+			}
+			if m.state == stateChartList && !m.loading {
+				return m.toggleChartSort()
+			}
+
+		case "A":
+			if m.state == stateRepoList && m.opts.artifactHub && !m.loading {
+				return m.openInput(inputHubSearch, "🔎 Search ArtifactHub for repositories:"), nil
+			}
+			if m.state == stateVersionList && !m.loading {
+				return m.confirmArchive()
+			}
+
+		case "shift+up", "shift+down":
+			if m.state == stateRepoList {
+				delta := 1
+				if msg.String() == "shift+up" {
+					delta = -1
+				}
+				return m.moveRepo(delta)
+			}
+
+		case "z":
+			if m.state == stateRepoList && m.groupRepos {
+				m = m.toggleSection()
+			}
+
+		case "Z":
+			if m.state == stateRepoList && m.groupRepos {
+				m.collapsed = make(map[string]bool)
+				m = m.applyRepoView()
+			}
+
+		case "left", "h", "right", "l":
+			if m.state == stateRepoList {
+				delta := 1
+				if msg.String() == "left" || msg.String() == "h" {
+					delta = -1
+				}
+				m = m.moveColumn(delta)
+			}
 
 		case "up", "k":
 			switch m.state {
@@ -267,6 +985,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.cursor > 0 {
 					m.cursor--
 				}
+			case stateHubSearch, stateValuesFiles, stateRepoHealth:
+				if m.cursor > 0 {
+					m.cursor--
+				}
 			default:
 				// No cursor movement for other states
 			}
@@ -285,6 +1007,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.cursor < len(m.versions)-1 {
 					m.cursor++
 				}
+			case stateHubSearch:
+				if m.cursor < len(m.hubRepos)-1 {
+					m.cursor++
+				}
+			case stateValuesFiles:
+				if m.cursor < len(m.valuesFiles)-1 {
+					m.cursor++
+				}
+			case stateRepoHealth:
+				if m.cursor < len(m.health)-1 {
+					m.cursor++
+				}
 			default:
 				// No cursor movement for other states
 			}
@@ -293,123 +1027,348 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.state {
 			case stateRepoList:
 				if len(m.repos) > 0 {
-					m.selectedRepo = m.cursor
-					m.cursor = 0
-					m.loading = true
-					m.state = stateChartList
-					return m, loadCharts(m.repos[m.selectedRepo].Name)
+					return m.openRepo(m.cursor)
 				}
 			case stateChartList:
 				if len(m.charts) > 0 {
-					m.selectedChart = m.cursor
-					m.cursor = 0
-					m.loading = true
-					m.state = stateVersionList
-					return m, loadVersions(m.charts[m.selectedChart].Name)
+					return m.openChart(m.cursor)
 				}
 			case stateVersionList:
-				if len(m.versions) > 0 {
-					m.selectedVersion = m.cursor
-					m.loading = true
-					m.state = stateDownload
-					return m, downloadValues(m.versions[m.selectedVersion].Name, m.versions[m.selectedVersion].Version)
+				return m.runAction(actionDownload)
+			case stateHubSearch:
+				if len(m.hubRepos) > 0 && !m.loading {
+					return m.openHubRepo(m.cursor)
+				}
+			case stateValuesFiles:
+				if len(m.valuesFiles) > 0 && !m.loading {
+					return m.downloadValuesFile(m.cursor)
+				}
+			case stateRepoHealth:
+				if m.cursor < len(m.health) && !m.checking {
+					return m.openHealthRepo(m.cursor)
 				}
-			case stateComplete:
-				// Any key press in complete state should exit
-				return m, tea.Quit
 			default:
 				// No action for other states
 			}
 
 		case "backspace", "esc":
-			switch m.state {
-			case stateChartList:
-				m.state = stateRepoList
-				m.cursor = m.selectedRepo
-				m.charts = nil
-			case stateVersionList:
-				m.state = stateChartList
-				m.cursor = m.selectedChart
-				m.versions = nil
-			case stateComplete:
-				return m, tea.Quit
-			default:
-				// No back action for other states
+			if prev, ok := m.back(); ok {
+				return prev, nil
 			}
 
 		default:
-			// Handle "any key to exit" in complete state
-			if m.state == stateComplete {
-				return m, tea.Quit
-			}
-
 			// Number shortcuts (for current page only)
-			if len(msg.String()) == 1 {
-				if num, err := strconv.Atoi(msg.String()); err == nil && num >= 1 && num <= pageSize {
-					switch m.state {
-					case stateRepoList:
-						pageStart := m.getPageStart()
-						absoluteIndex := pageStart + num - 1
-						if absoluteIndex < len(m.repos) {
-							m.selectedRepo = absoluteIndex
-							m.cursor = 0
-							m.loading = true
-							m.state = stateChartList
-							return m, loadCharts(m.repos[m.selectedRepo].Name)
-						}
-					case stateChartList:
-						pageStart := m.getPageStart()
-						absoluteIndex := pageStart + num - 1
-						if absoluteIndex < len(m.charts) {
-							m.selectedChart = absoluteIndex
-							m.cursor = 0
-							m.loading = true
-							m.state = stateVersionList
-							return m, loadVersions(m.charts[m.selectedChart].Name)
-						}
-					case stateVersionList:
-						pageStart := m.getPageStart()
-						absoluteIndex := pageStart + num - 1
-						if absoluteIndex < len(m.versions) {
-							m.selectedVersion = absoluteIndex
-							m.loading = true
-							m.state = stateDownload
-							return m, downloadValues(m.versions[m.selectedVersion].Name, m.versions[m.selectedVersion].Version)
-						}
-					default:
-						// No number shortcuts for other states
+			if position, ok := shortcutPosition(msg.String()); ok {
+				absoluteIndex := m.getPageStart() + position
+				switch m.state {
+				case stateRepoList:
+					if absoluteIndex < len(m.repos) {
+						return m.openRepo(absoluteIndex)
+					}
+				case stateChartList:
+					if absoluteIndex < len(m.charts) {
+						return m.openChart(absoluteIndex)
 					}
+				case stateVersionList:
+					if absoluteIndex < len(m.versions) {
+						m.cursor = absoluteIndex
+						return m.runAction(actionDownload)
+					}
+				case stateHubSearch:
+					if absoluteIndex < len(m.hubRepos) && !m.loading {
+						return m.openHubRepo(absoluteIndex)
+					}
+				case stateValuesFiles:
+					if absoluteIndex < len(m.valuesFiles) && !m.loading {
+						return m.downloadValuesFile(absoluteIndex)
+					}
+				case stateRepoHealth:
+					if absoluteIndex < len(m.health) && !m.checking {
+						return m.openHealthRepo(absoluteIndex)
+					}
+				default:
+					// No number shortcuts for other states
 				}
 			}
 		}
 
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.rows = make(rowCache)
+		m.preview.height = m.viewportHeight()
+		m.preview.scroll(0)
+		m = m.resizeTabs()
+
 	case repoUpdateMsg:
+		if msg.warning != "" {
+			m.status = errorStyle.Render(msg.warning)
+		}
+		m.startup = startupProgress{step: 1, warning: msg.failed}
 		m.loading = true
 		return m, loadRepos()
 
 	case reposLoadedMsg:
-		m.repos = msg
+		m.rows = make(rowCache)
+		m.allRepos = orderRepos(msg, m.cfg.Order)
 		m.loading = false
 		m.state = stateRepoList
 		m.cursor = 0
+		m = m.applyRepoView()
+		reloaded := m.reload.active
+		m = m.restoreCursor()
+
+		var prefetch tea.Cmd
+		if m.opts.prefetch && len(m.allRepos) > 0 && !reloaded {
+			m.prefetching = true
+			m.prefetchDone = 0
+			m.prefetchTotal = len(m.allRepos)
+			prefetch = prefetchCharts(m.allRepos, m.opts.concurrency)
+		}
+		// Hiding unreachable repositories needs a check first
+		var check tea.Cmd
+		if m.hideUnreachable && m.health == nil {
+			m, check = m.startHealthCheck()
+		}
+		if m.target != "" {
+			var open tea.Cmd
+			m, open = m.openTarget()
+			return m, tea.Batch(prefetch, check, open)
+		}
+		return m, tea.Batch(prefetch, check)
 
 	case chartsLoadedMsg:
+		m.rows = make(rowCache)
 		m.charts = msg
 		m.loading = false
 		m.cursor = 0
+		m.chartCache[m.repos[m.selectedRepo].Name] = msg
+		var sortCmd, typesCmd tea.Cmd
+		if m.chartSort == chartSortVersions || m.favoritesFirst {
+			m, sortCmd = m.sortCharts()
+		}
+		m, typesCmd = m.withChartTypes()
+		m = m.restoreCursor()
+		if m.target != "" {
+			var open tea.Cmd
+			m, open = m.openTargetChart()
+			return m, tea.Batch(sortCmd, typesCmd, open)
+		}
+		return m, tea.Batch(sortCmd, typesCmd)
 
-	case versionsLoadedMsg:
-		m.versions = msg
+	case searchResultsMsg:
+		m.rows = make(rowCache)
+		m.charts = msg
 		m.loading = false
 		m.cursor = 0
+		var sortCmd, typesCmd tea.Cmd
+		if m.chartSort == chartSortVersions || m.favoritesFirst {
+			m, sortCmd = m.sortCharts()
+		}
+		m, typesCmd = m.withChartTypes()
+		return m.restoreCursor(), tea.Batch(sortCmd, typesCmd)
+
+	case versionCountsMsg:
+		m.counting = false
+		for name, count := range msg {
+			m.versionCounts[name] = count
+		}
+		if m.state == stateChartList && !m.loading && m.chartSort == chartSortVersions {
+			return m.resortCharts()
+		}
+
+	case chartTypesMsg:
+		m.checkingTypes = false
+		for name, chartType := range msg {
+			m.chartTypes[name] = chartType
+		}
+		m.rows = make(rowCache)
+		if m.state == stateChartList && !m.loading {
+			return m.withChartTypes()
+		}
+
+	case prefetchMsg:
+		m.prefetchDone++
+		if _, loaded := m.chartCache[msg.repo]; msg.ok && !loaded {
+			m.chartCache[msg.repo] = msg.charts
+		}
+		return m, waitForPrefetch(msg.ch)
+
+	case prefetchDoneMsg:
+		m.prefetching = false
+
+	case healthProgressMsg:
+		m.checked++
+		return m, waitForHealth(msg.ch)
+
+	case healthDoneMsg:
+		m = m.updateHealthDone(msg)
+
+	case versionsLoadedMsg:
+		m.rows = make(rowCache)
+		m.allVersions = msg
+		m.latest = latestVersion(msg)
+		m = m.applyAppVersionFilter()
+		m.loading = false
+		if m.reload.active {
+			m = m.restoreCursor()
+		} else {
+			m = m.cursorToPin()
+		}
+		if m.opts.artifactHub && singleVersionSuspicious(msg) && m.hubVersions.chart != msg[0].Name {
+			return m, loadHubVersions(msg[0].Name)
+		}
+
+	case hubVersionsMsg:
+		m.hubVersions = msg
 
 	case downloadCompleteMsg:
+		version := msg.version
+		m.downloaded[downloadKey(version.Name, version.Version)] = true
+		m = m.record(stepValues, version, msg.path, "")
+		m.loading = false
+		m.state = stateComplete
+		m.written = []string{msg.path}
+		if msg.empty {
+			m.message = fmt.Sprintf("Chart has empty default values — wrote empty file: %s", msg.path)
+		} else {
+			m.message = fmt.Sprintf("Successfully downloaded: %s", msg.path)
+		}
+		if msg.warning != "" {
+			m.status = errorStyle.Render(msg.warning)
+		}
+
+	case valuesFilesMsg:
+		m.loading = false
+		m.valuesFiles = msg
+
+	case valuesFileWrittenMsg:
+		m = m.record(stepValuesFile, msg.version, msg.path, msg.name)
+		m.loading = false
+		m.state = stateComplete
+		m.written = []string{msg.path}
+		m.message = fmt.Sprintf("Successfully downloaded %s: %s", msg.name, msg.path)
+
+	case subchartCompleteMsg:
+		m = m.record(stepSubchart, msg.version, msg.path, msg.subchart)
+		m.loading = false
+		m.state = stateComplete
+		m.written = []string{msg.path}
+		m.message = fmt.Sprintf("Successfully downloaded %s subchart values: %s", msg.subchart, msg.path)
+
+	case batchDownloadMsg:
+		for i, key := range msg.keys {
+			m.downloaded[key] = true
+			m = m.record(stepValues, m.selected[key], msg.paths[i], "")
+			delete(m.selected, key)
+		}
+		m.loading = false
+		m.state = stateComplete
+		m.written = msg.paths
+		m.message = fmt.Sprintf("Downloaded %d values files:\n   %s", len(msg.paths), strings.Join(msg.paths, "\n   "))
+		if len(msg.failed) > 0 {
+			m.message += "\n" + errorStyle.Render(fmt.Sprintf("❌ %d failed:\n   %s", len(msg.failed), strings.Join(msg.failed, "\n   ")))
+		}
+
+	case previewLoadedMsg:
+		m.loading = false
+		m.preview = newViewport(msg, m.viewportHeight())
+		if m.opts.colors() {
+			m.preview.highlight = highlightYAML
+			m.preview.styled = make(map[int]string)
+		}
+
+	case diffLoadedMsg:
+		m.loading = false
+		m.preview = newViewport(msg.output, m.viewportHeight())
+		m.preview.title = fmt.Sprintf("🔀 Diff of release %s against %s %s:", msg.release, msg.version.Name, msg.version.Version)
+
+	case pluginsLoadedMsg:
+		m.plugins = msg
+
+	case pullCompleteMsg:
+		m = m.record(stepPull, msg.version, msg.path, "")
 		m.loading = false
 		m.state = stateComplete
-		m.message = fmt.Sprintf("Successfully downloaded: %s", msg)
+		m.written = []string{msg.path}
+		m.message = fmt.Sprintf("Successfully pulled: %s", msg.path)
+
+	case detailsLoadedMsg:
+		m.details[msg.key] = msg.metadata
+
+	case configSavedMsg:
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("❌ Failed to save the %s: %v", msg.what, msg.err))
+		} else {
+			m.status = downloadedStyle.Render(fmt.Sprintf("💾 Saved the %s to %s", msg.what, m.opts.configPath))
+		}
+
+	case clusterVersionMsg:
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("⚠️  Cannot check Kubernetes compatibility: %v", msg.err))
+		} else {
+			m.kubeVersion = msg.version
+		}
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.status = errorStyle.Render(fmt.Sprintf("❌ Copy failed: %v", msg.err))
+		} else {
+			m.status = downloadedStyle.Render(fmt.Sprintf("📋 Copied: %s", msg.text))
+		}
+
+	case browserMsg:
+		m.status = browserStatus(msg)
+
+	case versionListMsg:
+		m = m.updateVersionList(msg)
+
+	case progressMsg:
+		return m.updateProgress(msg)
+
+	case helmEnvMsg:
+		m.helmEnv = helmEnv(msg)
+
+	case overrideValuesMsg:
+		return m.startOverrides(msg)
+
+	case watchTickMsg:
+		return m.updateWatchTick()
+
+	case idleTickMsg:
+		return m.updateIdleTick(msg)
+
+	case archiveProgressMsg:
+		m.archiveDone++
+		m.activity = fmt.Sprintf("📦 Archiving values %d/%d...", m.archiveDone, m.archiveTotal)
+		return m, waitForArchive(msg.ch)
+
+	case archiveDoneMsg:
+		return m.updateArchiveDone(msg)
+
+	case tabLoadedMsg:
+		return m.updateTabLoaded(msg)
+
+	case customActionMsg:
+		return m.updateCustomAction(msg)
+
+	case hubResultsMsg:
+		m.loading = false
+		m.hubRepos = msg.repos
+		if msg.err != nil {
+			m.hubErr = msg.err.Error()
+		}
+
+	case repoAddedMsg:
+		return m.updateRepoAdded(msg)
+
+	case watchRefreshMsg:
+		return m.updateWatchRefresh(msg)
 
 	case errorMsg:
+		sessionLog.log(logEntry{Event: "error", Error: string(msg)})
+		recentErrors.add(false, string(msg))
 		m.loading = false
+		m.reload = reloadTarget{}
 		m.state = stateError
 		m.error = string(msg)
 	}
@@ -417,75 +1376,187 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// titleContext describes where the user is, for the header
+func (m model) titleContext() string {
+	switch m.state {
+	case stateRepoUpdate:
+		return "Starting"
+	case stateRepoList:
+		return "Repositories"
+	case stateChartList:
+		if m.searchQuery != "" {
+			return fmt.Sprintf("'%s' in all repositories", m.searchQuery)
+		}
+		if m.selectedRepo < len(m.repos) {
+			return m.repos[m.selectedRepo].Name + " charts"
+		}
+	case stateVersionList:
+		if m.selectedChart < len(m.charts) {
+			return m.chartLabel(m.charts[m.selectedChart].Name) + " versions"
+		}
+	case stateRepoHealth:
+		return "Repository reachability"
+	case stateValuesFiles:
+		if m.selectedVersion < len(m.versions) {
+			v := m.versions[m.selectedVersion]
+			return m.chartLabel(v.Name) + " " + v.Version + " values files"
+		}
+	case stateDownload, statePreview:
+		if m.state == statePreview && m.showingTabs && m.activeTab < len(m.tabs) {
+			v := m.tabs[m.activeTab].version
+			return m.chartLabel(v.Name) + " " + v.Version
+		}
+		if m.selectedVersion < len(m.versions) {
+			v := m.versions[m.selectedVersion]
+			return m.chartLabel(v.Name) + " " + v.Version
+		}
+	case stateHubSearch:
+		return fmt.Sprintf("'%s' on ArtifactHub", m.hubQuery)
+	case stateComplete:
+		return "Done"
+	case stateError:
+		return "Error"
+	}
+	return ""
+}
+
 // View renders the current state of the application
 func (m model) View() string {
+	if m.tooSmall() {
+		return fmt.Sprintf("Terminal too small (%dx%d).\nPlease resize to at least %dx%d.\nPress q to quit.", m.width, m.height, minWidth, minHeight)
+	}
+
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render("🚀 Helm Chart Browser"))
+	title := "🚀 Helm Chart Browser"
+	if context := m.titleContext(); context != "" {
+		title += " — " + context
+	}
+	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
 
 	switch m.state {
 	case stateRepoUpdate:
-		s.WriteString("🔄 Updating Helm repositories...\n")
+		s.WriteString(m.renderStartup())
 
 	case stateRepoList:
 		if m.loading {
 			s.WriteString("🔄 Loading repositories...\n")
+		} else if len(m.allRepos) == 0 {
+			s.WriteString("📭 No Helm repositories configured.\n\n")
+			s.WriteString(helpStyle.Render("Add one with: helm repo add <name> <url>"))
 		} else {
 			s.WriteString("🚀 Select a Helm repository:\n\n")
 
-			// Header
-			s.WriteString(fmt.Sprintf("%-4s %-20s %s\n", "", "REPOSITORY", "URL"))
-			s.WriteString(fmt.Sprintf("%-4s %-20s %s\n", "────", "────────────────────", "───────────────────────────────────"))
+			if m.repoColumns() > 1 {
+				s.WriteString(m.repoGrid())
+			} else {
+				// Header
+				s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-20s %s\n", "", "REPOSITORY", "URL"), len(m.repos)))
+				s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-20s %s\n", "────", "────────────────────", "───────────────────────────────────"), len(m.repos)))
 
-			start := m.getPageStart()
-			end := m.getPageEnd(len(m.repos))
+				start := m.getPageStart()
+				end := m.getPageEnd(len(m.repos))
 
-			for i := start; i < end; i++ {
-				repo := m.repos[i]
+				prevGroup := ""
+				if m.groupRepos && start > 0 {
+					prevGroup = m.rowGroup(m.repos[start-1])
+				}
 
-				// Format number
-				numStr := fmt.Sprintf("%d.", i+1)
+				for i := start; i < end; i++ {
+					repo := m.repos[i]
 
-				// Format repository name with color
-				repoName := chartVersionStyle.Render(fmt.Sprintf("%-20s", repo.Name))
+					// A collapsed section is a row of its own: its header
+					if repo.section != "" {
+						line := fmt.Sprintf("%-4s %s", m.rowLabel(i), m.groupHeader(repo.section))
+						if i == m.cursor {
+							line = selectedStyle.Render("► ") + line
+						} else {
+							line = "  " + line
+						}
+						s.WriteString(m.gutter(i, len(m.repos)) + line + "\n")
+						prevGroup = repo.section
+						continue
+					}
 
-				// Format URL with color
-				repoURL := appVersionStyle.Render(repo.URL)
+					// Section headers
+					if group := m.repoGroup(repo.Name); m.groupRepos && (i == start || group != prevGroup) {
+						s.WriteString(m.gutterBlock(m.groupHeader(group)+"\n", len(m.repos)))
+						prevGroup = group
+					}
 
-				line := fmt.Sprintf("%-4s %s %s", numStr, repoName, repoURL)
+					if sep := m.favoritesSeparator(i, start, func(i int) string { return m.repos[i].Name }); sep != "" && !m.groupRepos {
+						s.WriteString(m.gutterBlock(sep+"\n", len(m.repos)))
+					}
 
-				if i == m.cursor {
-					s.WriteString(selectedStyle.Render("► " + line))
-				} else {
-					s.WriteString("  " + line)
+					key := rowKey{state: m.state, index: i, selected: i == m.cursor, width: m.width}
+					s.WriteString(m.gutter(i, len(m.repos)))
+					s.WriteString(m.rows.row(key, rowData(m.rowLabel(i), repo.Name, repo.URL, fmt.Sprint(m.isFavorite(repo.Name))), func() string {
+						// Format number
+						numStr := m.rowLabel(i)
+
+						// Format repository name with color
+						repoName := chartVersionStyle.Render(fmt.Sprintf("%-20s", repo.Name))
+
+						// Format URL with color
+						repoURL := appVersionStyle.Render(repo.URL)
+
+						line := fmt.Sprintf("%-4s %s %s", numStr, repoName, repoURL)
+						if m.isFavorite(repo.Name) {
+							line += " " + groupHeaderStyle.Render("★")
+						}
+
+						if i == m.cursor {
+							return selectedStyle.Render("► " + line)
+						}
+						return "  " + line
+					}))
+					s.WriteString("\n")
 				}
-				s.WriteString("\n")
 			}
 
 			s.WriteString("\n")
 
 			// Show pagination info
-			if len(m.repos) > pageSize {
-				totalPages := (len(m.repos) + pageSize - 1) / pageSize
+			if info := m.scrollInfo(len(m.repos), "repositories"); info != "" {
+				s.WriteString(helpStyle.Render(info))
+			} else if totalPages := m.getTotalPages(); totalPages > 1 {
 				currentPage := m.getCurrentPage() + 1
-				paginationInfo := fmt.Sprintf("📄 Page %d of %d • %d total repositories", currentPage, totalPages, len(m.repos))
+				paginationInfo := fmt.Sprintf("📄 Page %d of %d • %d total repositories", currentPage, totalPages, m.repoCount())
 				s.WriteString(helpStyle.Render(paginationInfo))
-			} else if len(m.repos) > 1 {
-				totalInfo := fmt.Sprintf("📄 %d repositories available", len(m.repos))
+			} else if m.repoCount() > 1 {
+				totalInfo := fmt.Sprintf("📄 %d repositories available", m.repoCount())
 				s.WriteString(helpStyle.Render(totalInfo))
 			}
+
+			if m.prefetching {
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render(fmt.Sprintf("⏳ Prefetching charts %d/%d", m.prefetchDone, m.prefetchTotal)))
+			}
+			if hidden := m.hiddenUnreachable(); hidden > 0 {
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render(fmt.Sprintf("🙈 %d unreachable repositories hidden (R to review)", hidden)))
+			}
 		}
 
 	case stateChartList:
 		if m.loading {
-			s.WriteString("🔄 Loading charts...\n")
+			// Placeholder rows keep the layout steady until the charts arrive
+			s.WriteString("🔄 Loading charts...\n\n")
+			s.WriteString(m.chartSkeleton())
 		} else {
-			s.WriteString(fmt.Sprintf("📊 Charts in repository '%s':\n\n", m.repos[m.selectedRepo].Name))
+			if m.searchQuery != "" {
+				if m.opts.exact {
+					s.WriteString(fmt.Sprintf("🔍 Charts named '%s' in all repositories:\n\n", m.searchQuery))
+				} else {
+					s.WriteString(fmt.Sprintf("🔍 Charts matching '%s' in all repositories:\n\n", m.searchQuery))
+				}
+			} else {
+				s.WriteString(fmt.Sprintf("📊 Charts in repository '%s':\n\n", m.repos[m.selectedRepo].Name))
+			}
 
-			// Header
-			s.WriteString(fmt.Sprintf("%-4s %-30s %s\n", "", "CHART NAME", "VERSION"))
-			s.WriteString(fmt.Sprintf("%-4s %-30s %s\n", "────", "──────────────────────────────", "───────"))
+			// Header, with a version count column when sorting by it
+			s.WriteString(m.gutterBlock(m.chartListHeader(), len(m.charts)))
 
 			start := m.getPageStart()
 			end := m.getPageEnd(len(m.charts))
@@ -493,30 +1564,71 @@ func (m model) View() string {
 			for i := start; i < end; i++ {
 				chart := m.charts[i]
 
-				// Format number
-				numStr := fmt.Sprintf("%d.", i+1)
+				count := ""
+				if m.chartSort == chartSortVersions {
+					count = "…"
+					if n, ok := m.versionCounts[chart.Name]; ok {
+						count = fmt.Sprint(n)
+					}
+				}
+
+				if sep := m.favoritesSeparator(i, start, func(i int) string { return m.charts[i].Name }); sep != "" {
+					s.WriteString(m.gutterBlock(sep+"\n", len(m.charts)))
+				}
 
-				// Format chart name with color
-				chartName := chartVersionStyle.Render(fmt.Sprintf("%-30s", strings.TrimPrefix(chart.Name, m.repos[m.selectedRepo].Name+"/")))
+				key := rowKey{state: m.state, index: i, selected: i == m.cursor, width: m.width}
+				s.WriteString(m.gutter(i, len(m.charts)))
+				s.WriteString(m.rows.row(key, rowData(m.rowLabel(i), chart.Name, chart.Version, chart.AppVersion, m.chartLabel(chart.Name), count, m.columnValues(chart), fmt.Sprint(m.appVersionColumn, m.chartDownloaded(chart.Name), m.isLibrary(chart.Name), m.isFavorite(chart.Name))), func() string {
+					// Format number
+					numStr := m.rowLabel(i)
+
+					// Format chart name with color
+					chartName := chartVersionStyle.Render(fmt.Sprintf("%-30s", m.chartLabel(chart.Name)))
+
+					// Format version with color
+					chartVer := appVersionStyle.Render(fmt.Sprintf("v%s", chart.Version))
+					if m.appVersionColumn {
+						appVer := chart.AppVersion
+						if appVer == "" {
+							appVer = "—"
+						}
+						chartVer = chartVersionStyle.Render(appVer)
+					}
 
-				// Format version with color
-				chartVer := appVersionStyle.Render(fmt.Sprintf("v%s", chart.Version))
+					if count != "" {
+						chartVer = appVersionStyle.Render(fmt.Sprintf("%-9s", count)) + " " + chartVer
+					}
 
-				line := fmt.Sprintf("%-4s %s %s", numStr, chartName, chartVer)
+					columns := m.columnValues(chart)
+					if columns != "" {
+						columns = appVersionStyle.Render(columns)
+					}
 
-				if i == m.cursor {
-					s.WriteString(selectedStyle.Render("► " + line))
-				} else {
-					s.WriteString("  " + line)
-				}
+					line := fmt.Sprintf("%-4s %s%s %s", numStr, chartName, columns, chartVer)
+					if m.chartDownloaded(chart.Name) {
+						line += " " + downloadedStyle.Render("✓")
+					}
+					if m.isLibrary(chart.Name) {
+						line += " " + develBadgeStyle.Render("📚 LIBRARY")
+					}
+					if m.isFavorite(chart.Name) {
+						line += " " + groupHeaderStyle.Render("★")
+					}
+
+					if i == m.cursor {
+						return selectedStyle.Render("► " + line)
+					}
+					return "  " + line
+				}))
 				s.WriteString("\n")
 			}
 
 			s.WriteString("\n")
 
 			// Show pagination info
-			if len(m.charts) > pageSize {
-				totalPages := (len(m.charts) + pageSize - 1) / pageSize
+			if info := m.scrollInfo(len(m.charts), "charts"); info != "" {
+				s.WriteString(helpStyle.Render(info))
+			} else if totalPages := m.getTotalPages(); totalPages > 1 {
 				currentPage := m.getCurrentPage() + 1
 				paginationInfo := fmt.Sprintf("📄 Page %d of %d • %d total charts", currentPage, totalPages, len(m.charts))
 				s.WriteString(helpStyle.Render(paginationInfo))
@@ -524,60 +1636,124 @@ func (m model) View() string {
 				totalInfo := fmt.Sprintf("📄 %d charts available", len(m.charts))
 				s.WriteString(helpStyle.Render(totalInfo))
 			}
+
+			if m.counting {
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render("⏳ Counting chart versions..."))
+			}
+			if m.checkingTypes {
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render("⏳ Checking chart types to hide library charts..."))
+			} else if m.hideLibrary {
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render("📚 Library charts hidden (L to show them)"))
+			}
+
+			// The chart version stays visible for the highlighted chart
+			if m.appVersionColumn && m.cursor < len(m.charts) {
+				chart := m.charts[m.cursor]
+				s.WriteString("\n")
+				s.WriteString(helpStyle.Render(fmt.Sprintf("ℹ️  %s • chart version v%s • app version %s", chart.Name, chart.Version, chart.AppVersion)))
+			}
 		}
 
 	case stateVersionList:
 		if m.loading {
 			s.WriteString("🔄 Loading versions...\n")
 		} else {
-			chartName := strings.TrimPrefix(m.charts[m.selectedChart].Name, m.repos[m.selectedRepo].Name+"/")
-			s.WriteString(fmt.Sprintf("📦 Versions of chart '%s':\n\n", chartName))
+			chartName := m.chartLabel(m.charts[m.selectedChart].Name)
+			if m.devel {
+				s.WriteString(fmt.Sprintf("📦 Versions of chart '%s' (including devel):\n\n", chartName))
+			} else {
+				s.WriteString(fmt.Sprintf("📦 Versions of chart '%s':\n\n", chartName))
+			}
+
+			if banner := m.deprecationBanner(); banner != "" {
+				s.WriteString(banner + "\n\n")
+			}
+			if banner := m.kubeVersionBanner(); banner != "" {
+				s.WriteString(banner + "\n\n")
+			}
+
+			if note := m.appVersionFilterNote(); note != "" {
+				s.WriteString(appVersionStyle.Render(note) + "\n\n")
+			}
+
+			if m.opts.watch > 0 {
+				s.WriteString(appVersionStyle.Render(m.watchNote()) + "\n\n")
+			}
 
 			// Header
-			s.WriteString(fmt.Sprintf("%-4s %-15s %-15s %s\n", "", "CHART VERSION", "APP VERSION", ""))
-			s.WriteString(fmt.Sprintf("%-4s %-15s %-15s %s\n", "────", "─────────────", "───────────", "──────"))
+			s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s   %-15s %-15s %s\n", "", "CHART VERSION", "APP VERSION", ""), len(m.versions)))
+			s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s   %-15s %-15s %s\n", "────", "─────────────", "───────────", "──────"), len(m.versions)))
 
 			start := m.getPageStart()
 			end := m.getPageEnd(len(m.versions))
 
 			for i := start; i < end; i++ {
 				version := m.versions[i]
+				isLatest := version.Version == m.latest
+
+				key := rowKey{state: m.state, index: i, selected: i == m.cursor, width: m.width}
+				s.WriteString(m.gutter(i, len(m.versions)))
+				s.WriteString(m.rows.row(key, rowData(m.rowLabel(i), version.Name, version.Version, version.AppVersion, fmt.Sprint(m.downloaded[downloadKey(version.Name, version.Version)], m.isSelected(version), isLatest, m.isPinned(version), m.newVersions[downloadKey(version.Name, version.Version)])), func() string {
+					// Format number
+					numStr := m.rowLabel(i)
+
+					// Format chart version with color
+					chartVer := chartVersionStyle.Render(fmt.Sprintf("%-15s", version.Version))
+
+					// Format app version with color
+					appVer := ""
+					if version.AppVersion != "" {
+						appVer = appVersionStyle.Render(fmt.Sprintf("%-15s", version.AppVersion))
+					} else {
+						appVer = fmt.Sprintf("%-15s", "─")
+					}
 
-				// Format number
-				numStr := fmt.Sprintf("%d.", i+1)
+					// Badge the highest version, wherever it is in the list
+					badge := ""
+					if isLatest && m.opts.latestBadge != "" {
+						badge = latestBadgeStyle.Render(m.opts.latestBadge)
+					}
 
-				// Format chart version with color
-				chartVer := chartVersionStyle.Render(fmt.Sprintf("%-15s", version.Version))
+					if m.isPinned(version) {
+						badge = latestBadgeStyle.Render("📌 PINNED") + " " + badge
+					}
 
-				// Format app version with color
-				appVer := ""
-				if version.AppVersion != "" {
-					appVer = appVersionStyle.Render(fmt.Sprintf("%-15s", version.AppVersion))
-				} else {
-					appVer = fmt.Sprintf("%-15s", "─")
-				}
+					if isPrerelease(version.Version) {
+						badge = develBadgeStyle.Render("🧪 DEVEL") + " " + badge
+					}
 
-				// Add "LATEST" badge for first version
-				badge := ""
-				if i == 0 {
-					badge = latestBadgeStyle.Render("🏷️  LATEST")
-				}
+					if m.newVersions[downloadKey(version.Name, version.Version)] {
+						badge = latestBadgeStyle.Render("🆕 NEW") + " " + badge
+					}
 
-				line := fmt.Sprintf("%-4s %s %s %s", numStr, chartVer, appVer, badge)
+					if m.downloaded[downloadKey(version.Name, version.Version)] {
+						badge = downloadedStyle.Render("✓ downloaded") + " " + badge
+					}
 
-				if i == m.cursor {
-					s.WriteString(selectedStyle.Render("► " + line))
-				} else {
-					s.WriteString("  " + line)
-				}
+					mark := " "
+					if m.isSelected(version) {
+						mark = selectedStyle.Render("●")
+					}
+
+					line := fmt.Sprintf("%-4s %s %s %s %s", numStr, mark, chartVer, appVer, badge)
+
+					if i == m.cursor {
+						return selectedStyle.Render("► " + line)
+					}
+					return "  " + line
+				}))
 				s.WriteString("\n")
 			}
 
 			s.WriteString("\n")
 
 			// Show pagination info with better formatting
-			if len(m.versions) > pageSize {
-				totalPages := (len(m.versions) + pageSize - 1) / pageSize
+			if info := m.scrollInfo(len(m.versions), "versions"); info != "" {
+				s.WriteString(helpStyle.Render(info))
+			} else if totalPages := m.getTotalPages(); totalPages > 1 {
 				currentPage := m.getCurrentPage() + 1
 				paginationInfo := fmt.Sprintf("📄 Page %d of %d • %d total versions", currentPage, totalPages, len(m.versions))
 				s.WriteString(helpStyle.Render(paginationInfo))
@@ -585,38 +1761,97 @@ func (m model) View() string {
 				totalInfo := fmt.Sprintf("📄 %d versions available", len(m.versions))
 				s.WriteString(helpStyle.Render(totalInfo))
 			}
+
+			if hint := m.singleVersionHint(); hint != "" {
+				s.WriteString(hint)
+			}
 		}
 
 	case stateDownload:
-		s.WriteString("⬇️  Downloading values.yaml...\n")
+		s.WriteString(m.activity + "\n")
+
+	case statePreview:
+		if m.loading {
+			s.WriteString("🔄 Loading values...\n")
+		} else {
+			s.WriteString(m.renderPreview())
+		}
+
+	case stateHubSearch:
+		s.WriteString(m.renderHubResults())
+
+	case stateValuesFiles:
+		s.WriteString(m.renderValuesFiles())
+
+	case stateRepoHealth:
+		s.WriteString(m.renderHealth())
 
 	case stateComplete:
 		s.WriteString("✅ " + m.message + "\n\n")
-		s.WriteString(selectedStyle.Render("🎉 Press any key to exit..."))
+		s.WriteString(selectedStyle.Render("🎉 Press Esc to keep browsing or any other key to exit..."))
 
 	case stateError:
 		s.WriteString(errorStyle.Render("❌ Error: " + m.error))
+		if hint := hintFor(m.error); hint != "" {
+			s.WriteString("\n\n💡 " + renderMarkdown(hint, m.opts.colors()))
+		}
 		s.WriteString("\n\nPress 'q' to quit.")
 
 	default:
 		s.WriteString("❓ Unknown state")
 	}
 
+	if m.state == stateVersionList && m.showDetails && !m.loading {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderDetails())
+	}
+
+	if m.state == stateVersionList && m.showTimeline && !m.loading && len(m.versions) > 0 {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderTimeline())
+	}
+
+	if m.confirmQuit {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderConfirmQuit())
+		return s.String()
+	}
+
+	if m.confirmingArchive {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderConfirmArchive())
+		return s.String()
+	}
+
+	if m.state == stateVersionList && m.menuOpen {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderMenu())
+		s.WriteString(helpStyle.Render("⌨️  Navigate: ↑/↓ • Run: Enter or the key shown • Close: Tab/Esc"))
+		return s.String()
+	}
+
+	if m.input.active {
+		s.WriteString(m.renderInput())
+		return s.String()
+	}
+
+	if m.status != "" {
+		s.WriteString("\n" + m.status + "\n")
+	}
+
 	// Help text
-	switch m.state {
-	case stateRepoList, stateChartList, stateVersionList:
-		s.WriteString("\n")
-		s.WriteString(helpStyle.Render("⌨️  Navigate: ↑/↓ arrows or j/k • Select: Enter/Space or number (1-9,0 for items on current page) • Back: Backspace/Esc • Quit: q/Ctrl+C"))
+	for _, line := range m.helpLines() {
 		s.WriteString("\n")
-		s.WriteString(helpStyle.Render("💡 Tip: Use arrow keys to navigate through pages of results"))
-	case stateComplete:
-		s.WriteString("\n")
-		s.WriteString(helpStyle.Render("⌨️  Press any key to exit the application"))
-	case stateError:
+		s.WriteString(helpStyle.Render(line))
+	}
+	switch m.state {
+	case stateRepoList, stateChartList, stateVersionList, stateHubSearch, stateValuesFiles, stateRepoHealth:
 		s.WriteString("\n")
-		s.WriteString(helpStyle.Render("⌨️  Press 'q' to quit the application"))
-	default:
-		// No help text for loading states
+		if m.opts.noPaging {
+			s.WriteString(helpStyle.Render("💡 Tip: Use arrow keys to scroll through the list"))
+		} else {
+			s.WriteString(helpStyle.Render("💡 Tip: Use arrow keys to navigate through pages of results"))
+		}
 	}
 
 	return s.String()
@@ -624,16 +1859,83 @@ func (m model) View() string {
 
 // the main is the entry point of the Helm Chart Browser application
 func main() {
-	// Check if helm is installed
-	if _, err := exec.LookPath("helm"); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: helm command not found. Please install Helm first.\n")
+	if len(os.Args) > 1 && os.Args[1] == "list" {
+		requireHelm()
+		os.Exit(runList(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	opts, err := parseOptions(os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	requireHelm()
+	commandTimeout = opts.helmTimeout
+	commandRetries = opts.retries
+	useProxy(opts.proxy)
+
+	// Interrupting stops the helm commands still running before exiting
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancelApp()
+	}()
+
+	if opts.logFile != "" {
+		sessionLog, err = openSessionLog(opts.logFile)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: failed to open log file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if opts.nonInteractive() {
+		code := runNonInteractive(opts, os.Stdout, os.Stderr)
+		shutdown()
+		os.Exit(code)
+	}
+
+	// Piped input is a list of charts to download rather than a terminal
+	if stdinPiped() {
+		code := runStdin(opts, os.Stdin, os.Stdout, os.Stderr)
+		shutdown()
+		os.Exit(code)
+	}
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		shutdown()
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(initialModel())
+	if !opts.colors() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	p := tea.NewProgram(initialModel(opts, cfg))
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	shutdown()
+	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	if m, ok := final.(model); ok && m.state != stateError {
+		_, _ = fmt.Fprint(os.Stderr, m.summary(time.Now()))
+	}
+}
+
+// requireHelm exits with an error when the helm binary is not installed
+func requireHelm() {
+	if _, err := exec.LookPath("helm"); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: helm command not found. Please install Helm first.\n")
+		os.Exit(1)
+	}
 }
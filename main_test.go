@@ -0,0 +1,2452 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseRepos(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty output", output: "", want: 0},
+		{name: "whitespace only", output: "\n", want: 0},
+		{name: "null", output: "null\n", want: 0},
+		{name: "empty object", output: "{}", want: 0},
+		{name: "empty array", output: "[]", want: 0},
+		{name: "repositories", output: `[{"name":"bitnami","url":"https://charts.bitnami.com/bitnami"},{"name":"argo","url":"https://argoproj.github.io/argo-helm"}]`, want: 2},
+		{name: "plain text error", output: "Error: no repositories to show", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, err := parseRepos([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(repos) != tt.want {
+				t.Errorf("parseRepos() returned %d repos, want %d", len(repos), tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeChartsWithDuplicatedRepo(t *testing.T) {
+	// helm search repo output when "bitnami" is configured twice
+	output := `[
+		{"name":"bitnami/redis","version":"19.0.1","app_version":"7.2.4","description":"Redis"},
+		{"name":"bitnami/redis","version":"19.0.1","app_version":"7.2.4","description":"Redis"},
+		{"name":"bitnami/nginx","version":"15.0.0","app_version":"1.25.0","description":"NGINX"},
+		{"name":"bitnami/nginx","version":"15.0.0","app_version":"1.25.0","description":"NGINX"},
+		{"name":"other/redis","version":"1.0.0","app_version":"7.0.0","description":"Redis"}
+	]`
+
+	var charts []HelmChart
+	if err := json.Unmarshal([]byte(output), &charts); err != nil {
+		t.Fatal(err)
+	}
+
+	got := dedupeCharts(charts)
+	want := []string{"bitnami/redis", "bitnami/nginx", "other/redis"}
+	if len(got) != len(want) {
+		t.Fatalf("dedupeCharts() returned %d charts, want %d", len(got), len(want))
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Errorf("chart %d = %q, want %q", i, got[i].Name, name)
+		}
+	}
+}
+
+func TestRepoNamesWithSpecialCharacters(t *testing.T) {
+	repos := []HelmRepo{
+		{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami"},
+		{Name: "my-bitnami", URL: "https://example.com/my-bitnami"},
+		{Name: "charts.example.io", URL: "https://charts.example.io"},
+		{Name: "team∕apps", URL: "https://example.com/team"},
+	}
+	charts := []HelmChart{
+		{Name: "bitnami/redis"},
+		{Name: "my-bitnami/redis"},
+		{Name: "charts.example.io/web"},
+		{Name: "chartsxexample.io/web"},
+		{Name: "team∕apps/api"},
+	}
+
+	tests := []struct {
+		repo      string
+		wantChart string
+		wantLabel string
+	}{
+		{repo: "bitnami", wantChart: "bitnami/redis", wantLabel: "redis"},
+		{repo: "my-bitnami", wantChart: "my-bitnami/redis", wantLabel: "redis"},
+		{repo: "charts.example.io", wantChart: "charts.example.io/web", wantLabel: "web"},
+		{repo: "team∕apps", wantChart: "team∕apps/api", wantLabel: "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			got := filterRepoCharts(charts, tt.repo)
+			if len(got) != 1 || got[0].Name != tt.wantChart {
+				t.Fatalf("filterRepoCharts(%q) = %v, want only %s", tt.repo, got, tt.wantChart)
+			}
+
+			if repo := repoForChart(repos, tt.wantChart); repo.Name != tt.repo {
+				t.Errorf("repoForChart(%q) = %q, want %q", tt.wantChart, repo.Name, tt.repo)
+			}
+
+			m := model{repos: []HelmRepo{{Name: tt.repo}}}
+			if label := m.chartLabel(tt.wantChart); label != tt.wantLabel {
+				t.Errorf("chartLabel(%q) = %q, want %q", tt.wantChart, label, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestRepoNameCasing(t *testing.T) {
+	repos := []HelmRepo{{Name: "Bitnami"}, {Name: "argo"}, {Name: "ARGO"}}
+	charts := []HelmChart{{Name: "Bitnami/redis"}, {Name: "argo/argo-cd"}, {Name: "ARGO/workflows"}}
+
+	tests := []struct {
+		name       string
+		wantIndex  int
+		wantCharts string
+	}{
+		{"Bitnami", 0, "[Bitnami/redis]"},
+		{"bitnami", 0, "[Bitnami/redis]"},
+		{"bitnami/", 0, "[Bitnami/redis]"},
+		{" BITNAMI// ", 0, "[Bitnami/redis]"},
+		// An exact match wins over one differing in casing
+		{"ARGO", 2, "[ARGO/workflows]"},
+		{"argo", 1, "[argo/argo-cd]"},
+		{"Argo", 1, "[argo/argo-cd ARGO/workflows]"},
+		{"jetstack", -1, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repoIndex(repos, tt.name); got != tt.wantIndex {
+				t.Errorf("repoIndex(%q) = %d, want %d", tt.name, got, tt.wantIndex)
+			}
+			var names []string
+			for _, chart := range filterRepoCharts(charts, tt.name) {
+				names = append(names, chart.Name)
+			}
+			if got := fmt.Sprint(names); got != tt.wantCharts {
+				t.Errorf("filterRepoCharts(%q) = %s, want %s", tt.name, got, tt.wantCharts)
+			}
+		})
+	}
+
+	m := model{allRepos: repos, hubRepos: []hubRepo{{URL: "https://example.com"}}}
+	next, _ := m.submitAddRepo("bitnami")
+	if err := next.(model).input.err; err != "a repository named Bitnami already exists" {
+		t.Errorf("adding bitnami: error %q", err)
+	}
+}
+func TestOrderRepos(t *testing.T) {
+	repos := []HelmRepo{{Name: "bitnami"}, {Name: "argo"}, {Name: "jetstack"}, {Name: "grafana"}}
+	tests := []struct {
+		name  string
+		order []string
+		want  string
+	}{
+		{"no saved order", nil, "bitnami argo jetstack grafana"},
+		{"saved first, rest in helm order", []string{"grafana", "argo"}, "grafana argo bitnami jetstack"},
+		{"removed repositories ignored", []string{"gone", "jetstack"}, "jetstack bitnami argo grafana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, repo := range orderRepos(repos, tt.order) {
+				names = append(names, repo.Name)
+			}
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("orderRepos(%v) = %s, want %s", tt.order, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoGrid(t *testing.T) {
+	repos := make([]HelmRepo, 25)
+	tests := []struct {
+		name    string
+		grid    bool
+		grouped bool
+		width   int
+		cursor  int
+		moves   []int
+		columns int
+		want    int
+	}{
+		{"off by default", false, false, 200, 0, []int{1}, 1, 0},
+		{"narrow terminal", true, false, 100, 0, []int{1}, 1, 0},
+		{"two columns", true, false, 140, 1, []int{1}, 2, 11},
+		{"three columns at most", true, false, 300, 0, []int{1, 1, 1}, 3, 20},
+		{"left stops at the first column", true, false, 200, 1, []int{-1}, 3, 1},
+		{"right stops at the last repository", true, false, 200, 6, []int{1, 1}, 3, 16},
+		{"grouped", true, true, 200, 0, []int{1}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{state: stateRepoList, repos: repos, width: tt.width, groupRepos: tt.grouped, cursor: tt.cursor}
+			m.opts.repoGrid = tt.grid
+			for _, delta := range tt.moves {
+				m = m.moveColumn(delta)
+			}
+			if got := m.repoColumns(); got != tt.columns {
+				t.Errorf("repoColumns() = %d, want %d", got, tt.columns)
+			}
+			if m.cursor != tt.want {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.want)
+			}
+		})
+	}
+
+	// Only the cells the number keys reach are numbered
+	m := model{state: stateRepoList, repos: repos, width: 200, rows: make(rowCache)}
+	m.opts.repoGrid = true
+	if grid := m.repoGrid(); !strings.Contains(grid, "10.") || strings.Contains(grid, "11.") {
+		t.Errorf("grid numbers cells past the number keys:\n%s", grid)
+	}
+
+	// With --no-paging each column holds the rows in view
+	m.opts.noPaging = true
+	m.height = listChrome + 5
+	if got := strings.Count(m.repoGrid(), "\n"); got != 2+5 {
+		t.Errorf("--no-paging grid has %d lines, want 7", got)
+	}
+	m.cursor = 16
+	if m = m.scrollList(); m.listOffset != 2 {
+		t.Errorf("listOffset = %d, want 2 so 15 repositories stay in view", m.listOffset)
+	}
+}
+
+func TestRepoSections(t *testing.T) {
+	m := model{
+		state:      stateRepoList,
+		groupRepos: true,
+		collapsed:  map[string]bool{},
+		rows:       make(rowCache),
+		repoSort:   sortHelm,
+		allRepos:   []HelmRepo{{Name: "bitnami"}, {Name: "dev"}, {Name: "stable"}, {Name: "misc"}},
+		cfg:        config{Groups: map[string]string{"bitnami": "Prod", "stable": "Prod", "dev": "Dev"}},
+	}
+	m = m.applyRepoView()
+	press := func(key string) {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	rows := func() string {
+		var names []string
+		for _, repo := range m.repos {
+			if repo.section != "" {
+				names = append(names, "["+repo.section+"]")
+			} else {
+				names = append(names, repo.Name)
+			}
+		}
+		return strings.Join(names, " ")
+	}
+
+	m.cursor = 1
+	press("z")
+	if got := rows(); got != "dev [Prod] misc" || m.cursor != 1 {
+		t.Fatalf("after collapsing Prod: %s with the cursor on %d, want dev [Prod] misc on its header", got, m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "▸ Prod (2)") {
+		t.Errorf("collapsed header missing from the view:\n%s", view)
+	}
+
+	press("z")
+	if got := rows(); got != "dev bitnami stable misc" || m.cursor != 1 {
+		t.Fatalf("z on the header: %s with the cursor on %d, want Prod expanded on bitnami", got, m.cursor)
+	}
+
+	press("z")
+	m.cursor = 2
+	press("z")
+	if got := rows(); got != "dev [Prod] [Other]" {
+		t.Fatalf("after collapsing Other too: %s", got)
+	}
+	m.cursor = 1
+	press("enter")
+	if got := rows(); got != "dev bitnami stable [Other]" || m.state != stateRepoList || m.cursor != 1 {
+		t.Errorf("Enter on the Prod header: %s in state %v, want only Prod expanded", got, m.state)
+	}
+
+	press("Z")
+	if got := rows(); got != "dev bitnami stable misc" {
+		t.Errorf("Z left %s, want every section expanded", got)
+	}
+}
+
+func TestRestoreCursor(t *testing.T) {
+	versions := func(names ...string) []HelmVersion {
+		var list []HelmVersion
+		for _, name := range names {
+			list = append(list, HelmVersion{Name: "bitnami/redis", Version: name})
+		}
+		return list
+	}
+	tests := []struct {
+		name   string
+		before int
+		loaded []HelmVersion
+		want   int
+	}{
+		{"same list", 1, versions("2.0.0", "1.0.0"), 1},
+		{"new version above", 1, versions("3.0.0", "2.0.0", "1.0.0"), 2},
+		{"highlighted version gone", 1, versions("3.0.0", "2.0.0", "0.9.0"), 1},
+		{"list got shorter", 1, versions("3.0.0"), 0},
+		{"list emptied", 1, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{state: stateVersionList, versions: versions("2.0.0", "1.0.0"), cursor: tt.before, charts: []HelmChart{{Name: "bitnami/redis"}}}
+			next, _ := m.reloadList()
+			m = next.(model)
+			m.versions = tt.loaded
+			m = m.restoreCursor()
+			if m.cursor != tt.want {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.want)
+			}
+			if m.reload.active {
+				t.Errorf("reload still active after the list was restored")
+			}
+		})
+	}
+}
+
+func TestGutter(t *testing.T) {
+	m := model{opts: options{gutter: true}, cursor: 46}
+	if got := m.gutter(46, 213); got != " 47/213 " {
+		t.Errorf("cursor row gutter = %q, want %q", got, " 47/213 ")
+	}
+	if got := m.gutter(45, 213); got != strings.Repeat(" ", 8) {
+		t.Errorf("other row gutter = %q, want blanks of the same width", got)
+	}
+	if got := m.gutterBlock("NAME\n────\n", 213); got != "        NAME\n        ────\n" {
+		t.Errorf("gutterBlock = %q", got)
+	}
+
+	m.opts.gutter = false
+	if got := m.gutter(46, 213) + m.gutterBlock("NAME\n", 213); got != "NAME\n" {
+		t.Errorf("without --gutter got %q", got)
+	}
+}
+
+func TestParseChartMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		chart       string
+		sources     string
+		homePage    string
+		annotations string
+	}{
+		{"home and sources", "name: redis\nhome: https://bitnami.com\nsources:\n  - https://github.com/bitnami/charts\n  - https://github.com/redis/redis\n", "https://github.com/bitnami/charts https://github.com/redis/redis", "https://bitnami.com", ""},
+		{"sources only", "name: redis\nsources:\n  - \"\"\n  - https://github.com/bitnami/charts\n", "https://github.com/bitnami/charts", "https://github.com/bitnami/charts", ""},
+		{"no links", "name: redis\n", "", "", ""},
+		{"annotations in order", "name: redis\nannotations:\n  category: Database\n  licenses: Apache-2.0\n", "", "", "category=Database licenses=Apache-2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := parseChartMetadata([]byte(tt.chart))
+			if err != nil {
+				t.Fatalf("parseChartMetadata() error = %v", err)
+			}
+			if got := strings.Join(metadata.Sources, " "); got != tt.sources {
+				t.Errorf("Sources = %q, want %q", got, tt.sources)
+			}
+			if got := metadata.homePage(); got != tt.homePage {
+				t.Errorf("homePage() = %q, want %q", got, tt.homePage)
+			}
+			var annotations []string
+			for _, a := range metadata.Annotations {
+				annotations = append(annotations, a.Key+"="+a.Value)
+			}
+			if got := strings.Join(annotations, " "); got != tt.annotations {
+				t.Errorf("Annotations = %q, want %q", got, tt.annotations)
+			}
+		})
+	}
+}
+
+func TestScrollList(t *testing.T) {
+	repos := make([]HelmRepo, 30)
+	tests := []struct {
+		name   string
+		offset int
+		cursor int
+		want   int
+	}{
+		{"cursor in view", 0, 5, 0},
+		{"cursor below the window", 0, 12, 3},
+		{"cursor above the window", 10, 4, 4},
+		{"window past the end", 25, 29, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{state: stateRepoList, repos: repos, height: 10 + listChrome, listOffset: tt.offset, cursor: tt.cursor}
+			m.opts.noPaging = true
+			m = m.scrollList()
+			if m.listOffset != tt.want {
+				t.Errorf("listOffset = %d, want %d", m.listOffset, tt.want)
+			}
+			if got, want := m.rowLabel(m.listOffset), "1."; got != want {
+				t.Errorf("first row labelled %q, want %q", got, want)
+			}
+			if got := m.getTotalPages(); got != 1 {
+				t.Errorf("getTotalPages() = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHelmChartExtraFields(t *testing.T) {
+	input := `{"name":"bitnami/redis","version":"19.0.1","app_version":"7.2.4","description":"Redis","deprecated":false,"keywords":["cache", "db"]}`
+
+	var chart HelmChart
+	if err := json.Unmarshal([]byte(input), &chart); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		column string
+		want   string
+	}{
+		{"name", "bitnami/redis"},
+		{"description", "Redis"},
+		{"deprecated", "false"},
+		{"keywords", `["cache","db"]`},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got := chartColumn(chart, tt.column); got != tt.want {
+			t.Errorf("chartColumn(%q) = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+
+	output, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"name":"bitnami/redis","version":"19.0.1","app_version":"7.2.4","description":"Redis","deprecated":false,"keywords":["cache","db"]}`
+	if string(output) != want {
+		t.Errorf("Marshal() = %s, want %s", output, want)
+	}
+}
+
+func TestRunHelmCancellation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake helm")
+	}
+
+	tests := []struct {
+		name    string
+		context func(ready <-chan struct{}) (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "cancelled",
+			context: func(ready <-chan struct{}) (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				go func() {
+					<-ready
+					cancel()
+				}()
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "timed out",
+			context: func(<-chan struct{}) (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 300*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			pidFile := filepath.Join(dir, "pid")
+			script := "#!/bin/sh\necho $$ > " + pidFile + ".tmp\nmv " + pidFile + ".tmp " + pidFile + "\nexec sleep 30\n"
+			if err := os.WriteFile(filepath.Join(dir, "helm"), []byte(script), 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			// Cancel once the fake helm has started
+			ready := make(chan struct{})
+			go func() {
+				defer close(ready)
+				for i := 0; i < 100; i++ {
+					if _, err := os.Stat(pidFile); err == nil {
+						return
+					}
+					time.Sleep(20 * time.Millisecond)
+				}
+			}()
+			ctx, cancel := tt.context(ready)
+			defer cancel()
+
+			start := time.Now()
+			_, err := runHelm(ctx, "repo", "update")
+			if err == nil {
+				t.Fatal("runHelm() succeeded, want an error")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("runHelm() error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("runHelm() returned after %s, want it to stop on cancellation", elapsed)
+			}
+
+			data, err := os.ReadFile(pidFile)
+			if err != nil {
+				t.Fatalf("fake helm did not start: %v", err)
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if process, err := os.FindProcess(pid); err == nil && process.Signal(syscall.Signal(0)) == nil {
+				t.Errorf("helm process %d is still running", pid)
+			}
+		})
+	}
+}
+
+func TestRunHelmErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake helm")
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{name: "stdout only", script: "echo ok; echo progress >&2", want: "ok\n"},
+		{name: "failure with stderr", script: "echo 'Error: no repositories to show' >&2; exit 1", wantErr: "helm repo list: exit status 1: Error: no repositories to show"},
+		{name: "timeout", script: "exec sleep 30", wantErr: "helm repo list: timed out after 200ms"},
+	}
+
+	old := commandTimeout
+	commandTimeout = 200 * time.Millisecond
+	defer func() { commandTimeout = old }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "helm"), []byte("#!/bin/sh\n"+tt.script+"\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			output, err := runHelm(context.Background(), "repo", "list")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("runHelm() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Fatalf("runHelm() error = %v, want %q", err, tt.wantErr)
+			}
+			if string(output) != tt.want {
+				t.Errorf("runHelm() output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+// fakeHelm is a HelmRunner that returns canned stdout by command line and
+// fails for commands it does not know
+type fakeHelm map[string]string
+
+func (f fakeHelm) Run(_ context.Context, args ...string) ([]byte, error) {
+	line := strings.Join(args, " ")
+	output, ok := f[line]
+	if !ok {
+		return nil, &commandError{args: append([]string{"helm"}, args...), stderr: "Error: unknown command", err: errors.New("exit status 1")}
+	}
+	return []byte(output), nil
+}
+
+// useFakeHelm runs helm commands through fake for the rest of the test
+func useFakeHelm(t *testing.T, fake HelmRunner) {
+	old := helmRunner
+	helmRunner = fake
+	// The environment cached from another helm would leak into the test
+	helmEnvCache.loaded = false
+	t.Cleanup(func() {
+		helmRunner = old
+		helmEnvCache.loaded = false
+	})
+}
+
+// helmFunc is a HelmRunner for tests that need more than canned output
+type helmFunc func(args ...string) ([]byte, error)
+
+func (f helmFunc) Run(_ context.Context, args ...string) ([]byte, error) {
+	return f(args...)
+}
+
+// chartArchive builds a .tgz holding files, by path
+func chartArchive(files map[string]string) []byte {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(data))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return archive.Bytes()
+}
+
+func TestRunPool(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit, n   int
+		maxWorkers int
+	}{
+		{name: "bounded by the limit", limit: 3, n: 12, maxWorkers: 3},
+		{name: "fewer jobs than workers", limit: 8, n: 2, maxWorkers: 2},
+		{name: "limit below one runs serially", limit: 0, n: 5, maxWorkers: 1},
+		{name: "no jobs", limit: 4, n: 0, maxWorkers: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+			calls := make([]atomic.Int32, tt.n)
+			runPool(tt.limit, tt.n, func(i int) {
+				now := running.Add(1)
+				for {
+					old := peak.Load()
+					if now <= old || peak.CompareAndSwap(old, now) {
+						break
+					}
+				}
+				// Hold the worker so the others get a chance to overlap
+				time.Sleep(5 * time.Millisecond)
+				calls[i].Add(1)
+				running.Add(-1)
+			})
+
+			if got := int(peak.Load()); got > tt.maxWorkers {
+				t.Errorf("got %d concurrent calls, want at most %d", got, tt.maxWorkers)
+			}
+			for i := range calls {
+				if got := calls[i].Load(); got != 1 {
+					t.Errorf("index %d ran %d times, want once", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchValues(t *testing.T) {
+	archive := chartArchive(map[string]string{"app/values.yaml": "replicaCount: 2\n", "app/charts/db/values.yaml": "db: true\n"})
+
+	failure := func(args []string, stderr string) error {
+		return &commandError{args: append([]string{"helm"}, args...), stderr: stderr, err: errors.New("exit status 1")}
+	}
+
+	tests := []struct {
+		name      string
+		showErr   string
+		pullFails bool
+		want      string
+		wantErr   string
+	}{
+		{"show values works", "", false, "replicaCount: 1\n", ""},
+		{"falls back to the archive", "Error: values not supported for this reference", false, "replicaCount: 2\n", ""},
+		{"missing chart is not pulled", "Error: chart \"app\" version \"1.0.0\" not found", false, "", "not found"},
+		{"both fail", "Error: values not supported for this reference", true, "", "could not retrieve the values of oci://example.com/app 1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pulled := false
+			useFakeHelm(t, helmFunc(func(args ...string) ([]byte, error) {
+				switch args[0] {
+				case "show":
+					if tt.showErr != "" {
+						return nil, failure(args, tt.showErr)
+					}
+					return []byte("replicaCount: 1\n"), nil
+				case "pull":
+					pulled = true
+					if tt.pullFails {
+						return nil, failure(args, "Error: failed to download")
+					}
+					return nil, os.WriteFile(filepath.Join(args[4], "app-1.0.0.tgz"), archive, 0644)
+				}
+				return nil, failure(args, "Error: unknown command")
+			}))
+
+			got, err := fetchValues("oci://example.com/app", "1.0.0")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				if strings.Contains(tt.showErr, "not found") && pulled {
+					t.Error("pulled a chart helm reported as missing")
+				}
+				return
+			}
+			if err != nil || string(got) != tt.want {
+				t.Errorf("got %q (%v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundledValuesFiles(t *testing.T) {
+	archive := chartArchive(map[string]string{
+		"app/Chart.yaml":             "name: app\n",
+		"app/values-production.yaml": "replicaCount: 3\n",
+		"app/values.yaml":            "replicaCount: 1\n",
+		"app/values/small.yml":       "replicaCount: 0\n",
+		"app/ci/test-values.yaml":    "test: true\n",
+		"app/charts/db/values.yaml":  "db: true\n",
+		"app/templates/values.yaml":  "{{ .Values }}\n",
+		"app/values-production.json": "{}\n",
+	})
+
+	files, err := bundledValuesFiles(archive)
+	if err != nil {
+		t.Fatalf("bundledValuesFiles: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.name)
+	}
+	if got, want := strings.Join(names, ", "), "values.yaml, values-production.yaml, values/small.yml"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	path := bundledValuesPath(HelmRepo{Name: "bitnami"}, HelmVersion{Name: "bitnami/app", Version: "1.0.0"}, "values/small.yml", options{format: formatJSON})
+	if path != "app-1.0.0-values-small.json" {
+		t.Errorf("bundledValuesPath = %s", path)
+	}
+}
+
+func TestCommandsWithFakeHelm(t *testing.T) {
+	fake := fakeHelm{
+		"repo list -o json":                               `[{"name":"bitnami","url":"https://charts.bitnami.com/bitnami"},{"name":"argo","url":"https://argoproj.github.io/argo-helm"}]`,
+		"search repo -o json -- bitnami/":                 `[{"name":"bitnami/redis","version":"19.0.1"},{"name":"my-bitnami/redis","version":"1.0.0"},{"name":"bitnami/nginx","version":"15.0.0"}]`,
+		"search repo --versions -o json -- bitnami/redis": `[{"name":"bitnami/redis","version":"19.0.1","app_version":"7.2.4"},{"name":"bitnami/redis","version":"19.0.0","app_version":"7.2.4"}]`,
+		"show values --version 19.0.1 -- bitnami/redis":   "replicaCount: 1\n",
+		"show all --version 19.0.1 -- bitnami/redis":      "name: redis\n---\nreplicaCount: 1\n---\n# Redis\n",
+	}
+	useFakeHelm(t, fake)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name  string
+		cmd   tea.Cmd
+		check func(t *testing.T, msg tea.Msg)
+	}{
+		{"repositories", loadRepos(), func(t *testing.T, msg tea.Msg) {
+			if repos, ok := msg.(reposLoadedMsg); !ok || len(repos) != 2 || repos[1].Name != "argo" {
+				t.Errorf("got %#v, want the two repositories", msg)
+			}
+		}},
+		{"charts of one repository", loadCharts("bitnami"), func(t *testing.T, msg tea.Msg) {
+			if charts, ok := msg.(chartsLoadedMsg); !ok || len(charts) != 2 {
+				t.Errorf("got %#v, want the two bitnami charts", msg)
+			}
+		}},
+		{"versions", loadVersions("bitnami/redis", false), func(t *testing.T, msg tea.Msg) {
+			if versions, ok := msg.(versionsLoadedMsg); !ok || len(versions) != 2 || versions[0].AppVersion != "7.2.4" {
+				t.Errorf("got %#v, want two versions", msg)
+			}
+		}},
+		{"helm failure", loadVersions("bitnami/missing", false), func(t *testing.T, msg tea.Msg) {
+			if err, ok := msg.(errorMsg); !ok || !strings.Contains(string(err), "Error: unknown command") {
+				t.Errorf("got %#v, want an error with helm's stderr", msg)
+			}
+		}},
+		{"values download", downloadValues(HelmRepo{Name: "bitnami"}, HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}, options{outputDir: dir, format: formatYAML, indent: 2}), func(t *testing.T, msg tea.Msg) {
+			done, ok := msg.(downloadCompleteMsg)
+			if !ok {
+				t.Fatalf("got %#v, want a completed download", msg)
+			}
+			if data, err := os.ReadFile(done.path); err != nil || string(data) != "replicaCount: 1\n" {
+				t.Errorf("wrote %q (%v), want the chart values", data, err)
+			}
+		}},
+		{"values download with source header", downloadValues(HelmRepo{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami"}, HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}, options{outputDir: dir, format: formatYAML, indent: 2, sourceHeader: true}), func(t *testing.T, msg tea.Msg) {
+			done, ok := msg.(downloadCompleteMsg)
+			if !ok {
+				t.Fatalf("got %#v, want a completed download", msg)
+			}
+			data, err := os.ReadFile(done.path)
+			if err != nil || !strings.HasPrefix(string(data), "# Downloaded from bitnami/redis 19.0.1 on ") ||
+				!strings.Contains(string(data), "# Repository: bitnami (https://charts.bitnami.com/bitnami)\nreplicaCount: 1\n") {
+				t.Fatalf("wrote %q (%v), want the source header above the values", data, err)
+			}
+			doc, err := parseYAML(data)
+			if err != nil || string(marshalYAML(doc, 2)) != "replicaCount: 1\n" {
+				t.Errorf("header changed the parsed values: %v", err)
+			}
+		}},
+		{"show all download", downloadValues(HelmRepo{Name: "bitnami"}, HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}, options{outputDir: dir, format: formatYAML, indent: 2, show: showAll}), func(t *testing.T, msg tea.Msg) {
+			done, ok := msg.(downloadCompleteMsg)
+			if !ok || filepath.Base(done.path) != "redis-19.0.1-show-all.txt" {
+				t.Fatalf("got %#v, want redis-19.0.1-show-all.txt", msg)
+			}
+			if data, err := os.ReadFile(done.path); err != nil || !strings.Contains(string(data), "# Redis") {
+				t.Errorf("wrote %q (%v), want the whole helm show all output", data, err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, tt.cmd())
+		})
+	}
+}
+
+func TestArchiveVersions(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"show values --version 3.0.0 -- bitnami/redis": "replicaCount: 2\n",
+		"show values --version 2.0.0 -- bitnami/redis": "replicaCount: 1\n",
+		"show values --version 1.0.0 -- bitnami/redis": "replicaCount: 1\n",
+	})
+	versions := []HelmVersion{
+		{Name: "bitnami/redis", Version: "3.0.0"},
+		{Name: "bitnami/redis", Version: "2.0.0"},
+		{Name: "bitnami/redis", Version: "1.0.0"},
+		{Name: "bitnami/redis", Version: "0.1.0"},
+	}
+
+	tests := []struct {
+		name    string
+		dedupe  bool
+		written string
+		skipped string
+	}{
+		{"every version", false, "1.0.0.yaml 2.0.0.yaml 3.0.0.yaml", ""},
+		{"identical values skipped", true, "1.0.0.yaml 3.0.0.yaml", "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := options{concurrency: 2, format: formatYAML, indent: 2, archiveDedupe: tt.dedupe}
+
+			var done archiveDoneMsg
+			progress := 0
+			for msg := archiveVersions(HelmRepo{Name: "bitnami"}, versions, dir, opts)(); ; {
+				if p, ok := msg.(archiveProgressMsg); ok {
+					progress++
+					msg = waitForArchive(p.ch)()
+					continue
+				}
+				done = msg.(archiveDoneMsg)
+				break
+			}
+
+			if progress != len(versions) {
+				t.Errorf("got %d progress reports, want %d", progress, len(versions))
+			}
+			var names []string
+			for _, path := range done.paths {
+				names = append(names, filepath.Base(path))
+			}
+			if got := strings.Join(names, " "); got != tt.written {
+				t.Errorf("wrote %s, want %s", got, tt.written)
+			}
+			if got := strings.Join(done.skipped, " "); got != tt.skipped {
+				t.Errorf("skipped %q, want %q", got, tt.skipped)
+			}
+			if len(done.failed) != 1 || !strings.HasPrefix(done.failed[0], "0.1.0: ") {
+				t.Errorf("failed = %v, want 0.1.0 to fail", done.failed)
+			}
+		})
+	}
+}
+
+func TestChartListPagination(t *testing.T) {
+	var charts []HelmChart
+	for i := 0; i < 25; i++ {
+		charts = append(charts, HelmChart{Name: fmt.Sprintf("bitnami/chart-%02d", i), Version: "1.0.0"})
+	}
+
+	m := initialModel(options{concurrency: defaultConcurrency, indent: 2, sortRepos: sortHelm, sortCharts: chartSortName}, config{})
+	m.state = stateChartList
+	m.repos = []HelmRepo{{Name: "bitnami"}}
+	next, _ := m.Update(chartsLoadedMsg(charts))
+	m = next.(model)
+
+	if got := m.getTotalPages(); got != 3 {
+		t.Fatalf("getTotalPages() = %d, want 3", got)
+	}
+	for i := 0; i < pageSize; i++ {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = next.(model)
+	}
+	if page, cursor := m.getCurrentPage(), m.cursor; page != 1 || cursor != pageSize {
+		t.Errorf("after %d downs: page %d, cursor %d; want page 1, cursor %d", pageSize, page, cursor, pageSize)
+	}
+	if start, end := m.getPageStart(), m.getPageEnd(len(m.charts)); start != 10 || end != 20 {
+		t.Errorf("page bounds = %d-%d, want 10-20", start, end)
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+	}{
+		{"newest first", []string{"2.0.0", "1.9.0", "1.10.0"}, "2.0.0"},
+		{"numeric not lexical", []string{"1.9.0", "1.10.0", "1.2.0"}, "1.10.0"},
+		{"prerelease skipped", []string{"2.0.0-rc.1", "1.5.0"}, "1.5.0"},
+		{"only prereleases", []string{"2.0.0-rc.2", "2.0.0-rc.10", "2.0.0-beta.1"}, "2.0.0-rc.10"},
+		{"v prefix and build metadata", []string{"v1.2.3+build.7", "v1.2.2"}, "v1.2.3+build.7"},
+		{"unparsable versions lose", []string{"latest", "0.0.1"}, "0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var versions []HelmVersion
+			for _, v := range tt.versions {
+				versions = append(versions, HelmVersion{Version: v})
+			}
+			if got := latestVersion(versions); got != tt.want {
+				t.Errorf("latestVersion(%v) = %q, want %q", tt.versions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOverride(t *testing.T) {
+	values := "# Default values\nreplicaCount: 1 # replicas\nimage:\n  repository: redis\n  tag: \"7.2\"\n"
+
+	tests := []struct {
+		path    string
+		answer  string
+		want    string
+		wantErr bool
+	}{
+		{path: "replicaCount", answer: "3", want: "replicaCount: 3 # replicas"},
+		{path: "image.tag", answer: `"7.4"`, want: `  tag: "7.4"`},
+		{path: "image.repository", answer: "bitnami/redis", want: "  repository: bitnami/redis"},
+		{path: "image.tag", answer: "[a, b]", wantErr: true},
+		{path: "image", answer: "redis", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"="+tt.answer, func(t *testing.T) {
+			got, err := applyOverride([]byte(values), tt.path, tt.answer)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applyOverride(%s, %s) succeeded, want an error", tt.path, tt.answer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyOverride(%s, %s) failed: %v", tt.path, tt.answer, err)
+			}
+			if !strings.Contains(string(got), tt.want+"\n") || !strings.HasPrefix(string(got), "# Default values\n") {
+				t.Errorf("applyOverride(%s, %s) =\n%s\nwant a line %q", tt.path, tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifyValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		want   string
+	}{
+		{"drops null and empty", "a: 1\nb: null\nc: {}\nd: []\ne: ~\n", "a: 1\n"},
+		{"drops maps left empty", "a:\n  b:\n    c: null\n  d: x\n", "a:\n  d: x\n"},
+		{"keeps quoted empty strings", "a: \"\"\nb: false\n", "a: \"\"\nb: false\n"},
+		{"keeps list items", "a:\n  - null\n  - b: 1\n    c: []\n", "a:\n  - null\n  - b: 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transformValues([]byte(tt.values), options{format: formatYAML, indent: 2, minify: true})
+			if err != nil {
+				t.Fatalf("transformValues: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		json   string
+		flat   string
+	}{
+		{"YAML 1.1 booleans", "a: yes\nb: off\nc: \"yes\"\nd: y\n", `{"a":true,"b":false,"c":"yes","d":true}`, "a=true\nb=false\nc=yes\nd=true\n"},
+		{"YAML 1.1 numbers", "mode: 0755\nhex: 0x1F\nbig: 1_000\nver: 1.10\nport: \"8080\"\n", `{"big":1000,"hex":31,"mode":493,"port":"8080","ver":1.1}`, "mode=493\nhex=31\nbig=1000\nver=1.10\nport=8080\n"},
+		{"nulls", "a:\nb: ~\nc: null\n", `{"a":null,"b":null,"c":null}`, "a=null\nb=null\nc=null\n"},
+		{"anchors and aliases", "base: &base\n  tag: \"7.2\"\ncopy: *base\n", `{"base":{"tag":"7.2"},"copy":{"tag":"7.2"}}`, "base.tag=7.2\ncopy.tag=7.2\n"},
+		{"block scalars", "literal: |\n  a\n  b\nfolded: >-\n  a\n  b\n", `{"folded":"a b","literal":"a\nb\n"}`, "literal=a\\nb\\n\nfolded=a b\n"},
+		{"flow collections", "ports: [80, 443]\nlabels: {app: web, tier: \"1\"}\n", `{"labels":{"app":"web","tier":"1"},"ports":[80,443]}`, "ports[0]=80\nports[1]=443\nlabels.app=web\nlabels.tier=1\n"},
+		{"explicit string tag", "tag: !!str 1.10\n", `{"tag":"1.10"}`, "tag=1.10\n"},
+		{"keys YAML 1.1 would not read as strings", "\"on\": 1\n\"080\": x\n\"null\": yy\n\"~\": z\n\"0x1F\": w\nYes: v\n", `{"080":"x","0x1F":"w","Yes":"v","null":"yy","on":1,"~":"z"}`, "on=1\n080=x\nnull=yy\n~=z\n0x1F=w\nYes=v\n"},
+		{"escapes", "bad: \"\\uFFFD\\x01\\x7f\"\n", "{\"bad\":\"�\\u0001\x7f\"}", "bad=�\x01\x7f\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseYAML([]byte(tt.values))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			got, err := json.Marshal(doc.toValue())
+			if err != nil || string(got) != tt.json {
+				t.Errorf("values = %s (%v), want %s", got, err, tt.json)
+			}
+			if flat := string(flattenValues(doc)); flat != tt.flat {
+				t.Errorf("flat = %q, want %q", flat, tt.flat)
+			}
+
+			// Re-emitted values must read back the same
+			again, err := parseYAML(marshalYAML(doc, 2))
+			if err != nil {
+				t.Fatalf("re-emitted values do not parse: %v\n%s", err, marshalYAML(doc, 2))
+			}
+			if got, _ := json.Marshal(again.toValue()); string(got) != tt.json {
+				t.Errorf("re-emitted values = %s, want %s", got, tt.json)
+			}
+			// Keys are compared as helm reads them, not as the parser keeps them
+			for _, line := range strings.Split(string(marshalYAML(doc, 2)), "\n") {
+				if key, _, ok := strings.Cut(line, ": "); ok && !strings.HasPrefix(key, `"`) {
+					if _, ok := (&yamlNode{kind: yamlScalar, value: key}).toValue().(string); !ok {
+						t.Errorf("key %s is written without quotes", key)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := parseYAML([]byte("a: *missing\n")); err == nil {
+		t.Error("an unknown alias was accepted")
+	}
+}
+
+func TestNumberShortcuts(t *testing.T) {
+	var repos []HelmRepo
+	for i := 0; i < 25; i++ {
+		repos = append(repos, HelmRepo{Name: fmt.Sprintf("repo-%02d", i)})
+	}
+
+	keys := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "0"}
+	for position, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			// Second page, so the shortcut must be relative to the page
+			m := model{state: stateRepoList, repos: repos, cursor: pageSize, chartCache: map[string][]HelmChart{}}
+			for _, repo := range repos {
+				m.chartCache[repo.Name] = nil
+			}
+
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+			got := next.(model)
+			if got.state != stateChartList {
+				t.Fatalf("key %s: state = %v, want the chart list", key, got.state)
+			}
+			if want := pageSize + position; got.selectedRepo != want {
+				t.Errorf("key %s: opened %s, want %s", key, repos[got.selectedRepo].Name, repos[want].Name)
+			}
+			if label := pageNumber(got.selectedRepo); label != fmt.Sprintf("%d.", position+1) {
+				t.Errorf("key %s: row labelled %s", key, label)
+			}
+		})
+	}
+}
+
+func TestSpaceKey(t *testing.T) {
+	repos := []HelmRepo{{Name: "bitnami"}, {Name: "argo"}}
+	lines := strings.Repeat("key: value\n", 50)
+
+	tests := []struct {
+		name  string
+		model model
+		check func(t *testing.T, m model)
+	}{
+		{"selects in a list", model{state: stateRepoList, repos: repos, cursor: 1, chartCache: map[string][]HelmChart{"argo": nil}}, func(t *testing.T, m model) {
+			if m.state != stateChartList || m.selectedRepo != 1 {
+				t.Errorf("state = %v, selectedRepo = %d, want the argo chart list", m.state, m.selectedRepo)
+			}
+		}},
+		{"pages down in the preview", model{state: statePreview, preview: newViewport([]byte(lines), 10)}, func(t *testing.T, m model) {
+			if m.state != statePreview || m.preview.offset != 10 {
+				t.Errorf("state = %v, offset = %d, want the preview scrolled by a page", m.state, m.preview.offset)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, _ := tt.model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+			tt.check(t, next.(model))
+		})
+	}
+}
+
+func TestPreviewTabs(t *testing.T) {
+	versions := []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}, {Name: "bitnami/redis", Version: "19.0.0"}}
+	m := model{state: stateVersionList, versions: versions}
+	open := func(m model, cursor int, values string) model {
+		m.cursor = cursor
+		next, _ := m.openTab()
+		if values != "" {
+			next, _ = next.(model).updateTabLoaded(tabLoadedMsg{version: versions[cursor], values: []byte(values)})
+		}
+		return next.(model)
+	}
+
+	m = open(m, 0, "a: 1\n")
+	m, _ = m.keepTab().back()
+	m = open(m, 1, "b: 2\n")
+	if len(m.tabs) != 2 || m.activeTab != 1 || m.preview.lines[0] != "b: 2" {
+		t.Fatalf("after opening two tabs: %d tabs, active %d, showing %q", len(m.tabs), m.activeTab, m.preview.lines)
+	}
+
+	m = m.switchTab(1)
+	if m.activeTab != 0 || m.preview.lines[0] != "a: 1" {
+		t.Errorf("switchTab wrapped to tab %d showing %q, want the first tab", m.activeTab, m.preview.lines)
+	}
+
+	m, _ = m.keepTab().back()
+	m = open(m, 1, "")
+	if len(m.tabs) != 2 || m.activeTab != 1 || m.loading {
+		t.Errorf("reopening a version: %d tabs, active %d, loading %v; want its existing tab", len(m.tabs), m.activeTab, m.loading)
+	}
+
+	m = m.closeTab().closeTab()
+	if len(m.tabs) != 0 || m.state != stateVersionList || m.showingTabs {
+		t.Errorf("closing every tab left %d tabs in state %v", len(m.tabs), m.state)
+	}
+}
+
+func TestListOCITags(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		next    string
+		want    []string
+		wantErr error
+	}{
+		{name: "anonymous token, two pages", token: "anon", next: "/v2/charts/app/tags/list?last=1.9.0&n=100", want: []string{"2.0.0", "1.10.0+build.1", "1.9.0"}},
+		{name: "token refused", token: "", wantErr: errRegistryAuth},
+		{name: "next page on another host", token: "anon", next: "https://attacker.example/v2/charts/app/tags/list?last=1.9.0", wantErr: errForeignPage},
+		{name: "next page over plain http", token: "anon", next: "http://HOST/v2/charts/app/tags/list?last=1.9.0", wantErr: errForeignPage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/token" && tt.token == "":
+					w.WriteHeader(http.StatusUnauthorized)
+				case r.URL.Path == "/token":
+					_, _ = fmt.Fprintf(w, `{"token":%q}`, tt.token)
+				case r.Header.Get("Authorization") != "Bearer "+tt.token:
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:charts/app:pull"`, srv.URL))
+					w.WriteHeader(http.StatusUnauthorized)
+				case r.URL.Query().Get("last") == "":
+					next := strings.ReplaceAll(tt.next, "HOST", r.Host)
+					w.Header().Set("Link", "<"+next+`>; rel="next"`)
+					_, _ = fmt.Fprint(w, `{"tags":["1.9.0","sha256-abc.sig","2.1.0-rc.1"]}`)
+				default:
+					_, _ = fmt.Fprint(w, `{"tags":["2.0.0","1.10.0_build.1"]}`)
+				}
+			}))
+			defer srv.Close()
+
+			old := ociClient
+			ociClient = srv.Client()
+			defer func() { ociClient = old }()
+
+			ref := "oci://" + strings.TrimPrefix(srv.URL, "https://") + "/charts/app"
+			tags, err := listOCITags(ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("listOCITags() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []string
+			for _, v := range ociVersions(ref, tags, false) {
+				got = append(got, v.Version)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArtifactHub(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"repositories found", http.StatusOK, `[{"name":"bitnami","url":"https://charts.bitnami.com/bitnami","organization_name":"bitnami","verified_publisher":true}]`, "bitnami https://charts.bitnami.com/bitnami bitnami", ""},
+		{"no matches", http.StatusOK, `[]`, "", ""},
+		{"rate limited", http.StatusTooManyRequests, ``, "", "ArtifactHub returned 429 Too Many Requests"},
+		{"unexpected response", http.StatusOK, `<html>`, "", "failed to parse the ArtifactHub response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("name") != "redis" || r.URL.Query().Get("kind") != artifactHubKindHelm {
+					t.Errorf("query = %s, want a Helm repository search for redis", r.URL.RawQuery)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			old := artifactHubURL
+			artifactHubURL = srv.URL
+			defer func() { artifactHubURL = old }()
+
+			repos, err := queryArtifactHub("redis")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("queryArtifactHub() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("queryArtifactHub() error = %v", err)
+			}
+
+			var got []string
+			for _, repo := range repos {
+				got = append(got, repo.Name, repo.URL, repo.publisher())
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("repos = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of the request it forwards
+		proxied = r.URL.String()
+		_, _ = fmt.Fprint(w, `[{"name":"bitnami","url":"https://charts.bitnami.com/bitnami"}]`)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := parseProxy(proxy.URL)
+	if err != nil {
+		t.Fatalf("parseProxy() error = %v", err)
+	}
+	oldOCI, oldHub, oldCheck, oldURL := ociClient.Transport, artifactHubClient.Transport, repoCheckClient.Transport, artifactHubURL
+	defer func() {
+		ociClient.Transport, artifactHubClient.Transport, repoCheckClient.Transport, artifactHubURL = oldOCI, oldHub, oldCheck, oldURL
+	}()
+	useProxy(proxyURL)
+	artifactHubURL = "http://artifacthub.invalid/api/v1/repositories/search"
+
+	repos, err := queryArtifactHub("bitnami")
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("queryArtifactHub() = %v, %v, want one repository through the proxy", repos, err)
+	}
+	if !strings.HasPrefix(proxied, artifactHubURL+"?") {
+		t.Errorf("proxy got %q, want a request for %s", proxied, artifactHubURL)
+	}
+
+	for _, bad := range []string{"ftp://proxy:21", "http://", "://proxy"} {
+		if _, err := parseProxy(bad); err == nil {
+			t.Errorf("parseProxy(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestShouldConfirm(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         options
+		archive      bool
+		quit         bool
+		quitDownload bool
+	}{
+		{"default", options{}, true, true, true},
+		{"no-confirm-quit", options{noConfirmQuit: true}, true, false, true},
+		{"no-prompts", options{noPrompts: true}, false, true, true},
+		{"no-prompts with force", options{noPrompts: true, force: true}, false, false, false},
+		{"force alone", options{force: true}, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.shouldConfirm(promptArchive); got != tt.archive {
+				t.Errorf("archive prompt: got %v, want %v", got, tt.archive)
+			}
+			if got := tt.opts.shouldConfirm(promptQuit); got != tt.quit {
+				t.Errorf("quit prompt: got %v, want %v", got, tt.quit)
+			}
+			if got := tt.opts.shouldConfirm(promptQuitDownload); got != tt.quitDownload {
+				t.Errorf("quit during download prompt: got %v, want %v", got, tt.quitDownload)
+			}
+		})
+	}
+
+	// q while a download runs asks, and a second ctrl+c quits anyway
+	m := model{state: stateDownload, loading: true, activity: "⬇️  Downloading values.yaml..."}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = next.(model)
+	if cmd != nil || !m.confirmQuit || !strings.Contains(m.renderConfirmQuit(), "Downloading values.yaml") {
+		t.Fatalf("q during a download: asking %v, quit %v", m.confirmQuit, cmd != nil)
+	}
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("ctrl+c at the prompt did not quit")
+	}
+	m = model{state: stateDownload, loading: true, opts: options{noPrompts: true, force: true}}
+	if next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil || next.(model).confirmQuit {
+		t.Error("--no-prompts --force asked before quitting during a download")
+	}
+}
+
+func TestCustomActions(t *testing.T) {
+	invalid := []struct {
+		name   string
+		action customAction
+	}{
+		{"no name", customAction{Key: "K", Command: "ct lint"}},
+		{"no key", customAction{Name: "Lint", Command: "ct lint"}},
+		{"built-in key", customAction{Name: "Lint", Key: "v", Command: "ct lint"}},
+		{"space selects", customAction{Name: "Lint", Key: " ", Command: "ct lint"}},
+		{"reload key", customAction{Name: "Lint", Key: "ctrl+r", Command: "ct lint"}},
+		{"no command", customAction{Name: "Lint", Key: "K"}},
+		{"unknown field", customAction{Name: "Lint", Key: "K", Command: "ct lint {{.Tag}}"}},
+		{"broken template", customAction{Name: "Lint", Key: "K", Command: "ct lint {{.Name"}},
+		{"unclosed quote", customAction{Name: "Lint", Key: "K", Command: "ct lint --charts '{{.Name}}"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateActions([]customAction{tt.action}, defaultReloadKey); err == nil {
+				t.Errorf("validateActions accepted %+v", tt.action)
+			}
+		})
+	}
+
+	lint := customAction{Name: "Lint", Key: "K", Command: "ct lint --charts {{.Name}} --version={{.Version}}"}
+	if err := validateActions([]customAction{lint, {Name: "Lint again", Key: "K", Command: "ct lint"}}, defaultReloadKey); err == nil {
+		t.Error("validateActions accepted two actions with the same key")
+	}
+	args, err := lint.commandArgs(templateData(HelmRepo{Name: "my repo"}, HelmVersion{Name: "my repo/redis; rm -rf", Version: "19.0.1"}))
+	if err != nil {
+		t.Fatalf("commandArgs: %v", err)
+	}
+	want := []string{"ct", "lint", "--charts", "my repo/redis; rm -rf", "--version=19.0.1"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("commandArgs = %q, want %q", args, want)
+	}
+
+	words := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"spaced template action", "ct lint --charts {{ .Chart }}", []string{"ct", "lint", "--charts", "redis"}},
+		{"quotes inside a template action", `echo {{ printf "%s %s" .Chart .Version }}`, []string{"echo", "redis 19.0.1"}},
+		{"quoted arguments", `helm template "my release" {{.Name}} --set 'note=a b' ""`, []string{"helm", "template", "my release", "bitnami/redis", "--set", "note=a b", ""}},
+		{"escapes in double quotes", `echo "say \"{{.Version}}\""`, []string{"echo", `say "19.0.1"`}},
+		{"quoted template", `echo "{{.Chart}} {{.Version}}"x`, []string{"echo", "redis 19.0.1x"}},
+	}
+	for _, tt := range words {
+		t.Run(tt.name, func(t *testing.T) {
+			action := customAction{Name: "Run", Key: "K", Command: tt.command}
+			if err := validateActions([]customAction{action}, defaultReloadKey); err != nil {
+				t.Fatalf("validateActions: %v", err)
+			}
+			args, err := action.commandArgs(templateData(HelmRepo{Name: "bitnami"}, HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}))
+			if err != nil || strings.Join(args, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("commandArgs = %q (%v), want %q", args, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestListKeysReserved checks that every key update's list switch handles
+// is in listKeys, so no custom action can take it
+func TestListKeysReserved(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handled []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		sw, ok := n.(*ast.SwitchStmt)
+		if !ok {
+			return true
+		}
+		if tag, ok := sw.Tag.(*ast.Ident); !ok || tag.Name != "listKey" {
+			return true
+		}
+		for _, stmt := range sw.Body.List {
+			for _, expr := range stmt.(*ast.CaseClause).List {
+				if lit, ok := expr.(*ast.BasicLit); ok {
+					key, _ := strconv.Unquote(lit.Value)
+					handled = append(handled, key)
+				}
+			}
+		}
+		return false
+	})
+	if len(handled) == 0 {
+		t.Fatal("found no list key switch in main.go")
+	}
+
+	for _, key := range handled {
+		if !listKeys[key] {
+			t.Errorf("update handles %q, which is missing from listKeys", key)
+		}
+	}
+	for key := range listKeys {
+		action := customAction{Name: "Lint", Key: key, Command: "ct lint"}
+		if err := validateActions([]customAction{action}, defaultReloadKey); err == nil {
+			t.Errorf("a custom action may take %q", key)
+		}
+	}
+}
+
+func TestHelmfileReleases(t *testing.T) {
+	m := model{
+		allRepos: []HelmRepo{{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami"}},
+		selected: map[string]HelmVersion{},
+	}
+	for _, version := range []HelmVersion{
+		{Name: "bitnami/redis", Version: "19.0.1"},
+		{Name: "bitnami/redis", Version: "18.9.0"},
+		{Name: "oci://registry.example.com/charts/app", Version: "1.10"},
+	} {
+		m.selected[downloadKey(version.Name, version.Version)] = version
+	}
+
+	got := m.helmfileReleases()
+	_, body, _ := strings.Cut(got, "repositories:\n")
+	want := `  - name: "bitnami"
+    url: "https://charts.bitnami.com/bitnami"
+releases:
+  - name: "redis"
+    namespace: "default"
+    chart: "bitnami/redis"
+    version: "18.9.0"
+  - name: "redis-2"
+    namespace: "default"
+    chart: "bitnami/redis"
+    version: "19.0.1"
+  - name: "app"
+    namespace: "default"
+    chart: "oci://registry.example.com/charts/app"
+    version: "1.10"
+`
+	if body != want {
+		t.Errorf("helmfileReleases() =\n%s\nwant the repositories and releases\n%s", got, want)
+	}
+	if _, err := parseYAML([]byte(got)); err != nil {
+		t.Errorf("export is not valid YAML: %v", err)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	start := time.Now()
+	m := model{opts: options{idleTimeout: time.Minute}, lastInput: start}
+
+	tests := []struct {
+		name    string
+		at      time.Duration
+		loading bool
+		quits   bool
+	}{
+		{"before the timeout", 40 * time.Second, false, false},
+		{"after the timeout", time.Minute, false, true},
+		{"while loading", 2 * time.Minute, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.loading = tt.loading
+			_, cmd := m.updateIdleTick(idleTickMsg(start.Add(tt.at)))
+			if cmd == nil {
+				t.Fatal("no command returned")
+			}
+			// A further tick is scheduled rather than run at once, so only
+			// tea.Quit returns straight away
+			quit := make(chan bool, 1)
+			go func() {
+				_, ok := cmd().(tea.QuitMsg)
+				quit <- ok
+			}()
+			select {
+			case got := <-quit:
+				if got != tt.quits {
+					t.Errorf("quit = %v, want %v", got, tt.quits)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.quits {
+					t.Error("did not quit")
+				}
+			}
+		})
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if !next.(model).lastInput.After(start) {
+		t.Error("a key press did not reset the idle timer")
+	}
+}
+
+func TestKubeVersionAllows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+		wantErr    bool
+	}{
+		{">= 1.21.0-0", "v1.28.3-gke.100", true, false},
+		{">=1.21.0-0 <1.28.0-0", "1.28.3", false, false},
+		{">=1.21.0-0, <1.29.0-0", "1.28.3", true, false},
+		{"1.20 - 1.25", "1.25.9", true, false},
+		{"1.20 - 1.25", "1.26.0", false, false},
+		{"~1.27", "1.27.4", true, false},
+		{"~1.27", "1.28.0", false, false},
+		{"^1.25", "1.30.1", true, false},
+		{"1.x", "2.0.0", false, false},
+		{"<1.19 || >=1.29", "1.30.0", true, false},
+		{">1.28", "1.28.9", false, false},
+		{"%1.2", "1.28.0", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			got, err := kubeVersionAllows(tt.constraint, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("kubeVersionAllows(%q, %q) error = %v, wantErr %v", tt.constraint, tt.version, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("kubeVersionAllows(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkView(b *testing.B) {
+	m := initialModel(options{concurrency: defaultConcurrency, indent: 2, sortRepos: sortHelm}, config{})
+	m.loading = false
+	m.state = stateVersionList
+	m.width, m.height = 120, 40
+	m.repos = []HelmRepo{{Name: "bitnami"}}
+	m.charts = []HelmChart{{Name: "bitnami/redis"}}
+	for i := 0; i < 5000; i++ {
+		m.versions = append(m.versions, HelmVersion{
+			Name:       "bitnami/redis",
+			Version:    fmt.Sprintf("19.%d.%d", i/100, i%100),
+			AppVersion: "7.2.4",
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.cursor = i % pageSize
+		_ = m.View()
+	}
+}
+
+func TestRecentErrors(t *testing.T) {
+	var log errorLog
+	for i := 0; i < maxRecentErrors+5; i++ {
+		log.add(i%2 == 0, fmt.Sprintf("error %d", i))
+	}
+	entries := log.list()
+	if len(entries) != maxRecentErrors {
+		t.Fatalf("kept %d errors, want %d", len(entries), maxRecentErrors)
+	}
+	if entries[0].text != "error 5" || entries[len(entries)-1].text != fmt.Sprintf("error %d", maxRecentErrors+4) {
+		t.Errorf("kept %q to %q, want the newest errors", entries[0].text, entries[len(entries)-1].text)
+	}
+
+	// Failed commands are recorded even when nothing is shown on screen
+	old := recentErrors
+	recentErrors = &errorLog{}
+	t.Cleanup(func() { recentErrors = old })
+	if _, err := runCommand(context.Background(), "false"); err == nil {
+		t.Fatal("false succeeded")
+	}
+	entries = recentErrors.list()
+	if len(entries) != 1 {
+		t.Fatalf("recorded %d errors, want 1", len(entries))
+	}
+	if !strings.Contains(entries[0].text, "false") {
+		t.Errorf("recorded %q, want the failed command", entries[0].text)
+	}
+}
+
+func TestVersionListExport(t *testing.T) {
+	index := `apiVersion: v1
+entries:
+  redis:
+  - annotations:
+      category: Database
+    appVersion: 7.2.4
+    created: "2024-03-01T10:00:00Z"
+    version: 19.0.1
+  - appVersion: "7.2, rc"
+    created: "2024-02-01T10:00:00Z"
+    dependencies:
+    - name: common
+      version: 2.x.x
+    version: 19.0.0
+  redis-cluster:
+  - created: "2024-01-01T10:00:00Z"
+    version: 10.0.0
+generated: "2024-03-02T00:00:00Z"
+`
+	created := scanIndexCreated(strings.NewReader(index), "redis")
+	want := map[string]string{"19.0.1": "2024-03-01T10:00:00Z", "19.0.0": "2024-02-01T10:00:00Z"}
+	if fmt.Sprint(created) != fmt.Sprint(want) {
+		t.Errorf("created = %v, want %v", created, want)
+	}
+
+	versions := []HelmVersion{
+		{Name: "bitnami/redis", Version: "19.0.1", AppVersion: "7.2.4"},
+		{Name: "bitnami/redis", Version: "19.0.0", AppVersion: "7.2, rc"},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{exportCSV, "version,app_version,created\n19.0.1,7.2.4,2024-03-01T10:00:00Z\n19.0.0,\"7.2, rc\",2024-02-01T10:00:00Z\n"},
+		{exportJSON, `[
+  {
+    "version": "19.0.1",
+    "app_version": "7.2.4",
+    "created": "2024-03-01T10:00:00Z"
+  },
+  {
+    "version": "19.0.0",
+    "app_version": "7.2, rc",
+    "created": "2024-02-01T10:00:00Z"
+  }
+]
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := versionListExport(versions, created, options{exportFormat: tt.format, indent: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if opts, err := parseOptions([]string{"--format", "json"}, io.Discard); err != nil || opts.exportFormat != exportCSV {
+		t.Errorf("--format json changed the export format to %q (%v)", opts.exportFormat, err)
+	}
+	if _, err := parseOptions([]string{"--export-format", "xml"}, io.Discard); err == nil {
+		t.Error("--export-format xml was accepted")
+	}
+}
+
+func TestNavigationStack(t *testing.T) {
+	repos := []HelmRepo{{Name: "bitnami"}, {Name: "grafana"}, {Name: "jetstack"}}
+	charts := []HelmChart{{Name: "jetstack/cert-manager"}, {Name: "jetstack/trust-manager"}}
+	versions := []HelmVersion{{Name: "jetstack/trust-manager", Version: "0.12.0"}, {Name: "jetstack/trust-manager", Version: "0.11.0"}}
+	m := model{
+		state:      stateRepoList,
+		allRepos:   repos,
+		repos:      repos,
+		cursor:     2,
+		rows:       make(rowCache),
+		selected:   make(map[string]HelmVersion),
+		chartCache: map[string][]HelmChart{"jetstack": charts},
+	}
+
+	m, _ = m.openRepo(2)
+	m.cursor = 1
+	m, _ = m.openChart(1)
+	if m.state != stateVersionList || m.selectedRepo != 2 || m.selectedChart != 1 || len(m.navStack) != 2 {
+		t.Fatalf("after opening the chart: state %v, repo %d, chart %d, %d frames", m.state, m.selectedRepo, m.selectedChart, len(m.navStack))
+	}
+	m.loading, m.versions, m.cursor = false, versions, 1
+	m = m.toggleSelected()
+
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	next, _ := m.Update(esc)
+	m = next.(model)
+	if m.state != stateChartList || m.cursor != 1 || m.selectedRepo != 2 || len(m.charts) != 2 {
+		t.Fatalf("first esc: state %v, cursor %d, repo %d, %d charts", m.state, m.cursor, m.selectedRepo, len(m.charts))
+	}
+	if !m.isSelected(versions[1]) || len(m.selected) != 1 {
+		t.Errorf("first esc dropped the selection: %v", m.selectedKeys())
+	}
+
+	next, _ = m.Update(esc)
+	m = next.(model)
+	if m.state != stateRepoList || m.cursor != 2 || m.selectedRepo != 0 || len(m.navStack) != 0 {
+		t.Fatalf("second esc: state %v, cursor %d, repo %d, %d frames", m.state, m.cursor, m.selectedRepo, len(m.navStack))
+	}
+	if !m.isSelected(versions[1]) {
+		t.Errorf("second esc dropped the selection: %v", m.selectedKeys())
+	}
+
+	if _, ok := m.back(); ok {
+		t.Error("back from the first screen reported a screen to return to")
+	}
+}
+
+func TestDownloadSelected(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"show values --version 19.0.1 -- bitnami/redis": "replicaCount: 1\n",
+		"show values --version 18.0.0 -- bitnami/redis": "replicaCount: 2\n",
+	})
+	versions := []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}, {Name: "bitnami/redis", Version: "19.0.0"}, {Name: "bitnami/redis", Version: "18.0.0"}}
+	m := model{
+		state:      stateVersionList,
+		repos:      []HelmRepo{{Name: "bitnami"}},
+		versions:   versions,
+		selected:   make(map[string]HelmVersion),
+		downloaded: make(map[string]bool),
+		opts:       options{outputDir: t.TempDir(), format: formatYAML, indent: 2, concurrency: 2},
+	}
+	for m.cursor = range versions {
+		m = m.toggleSelected()
+	}
+	if len(m.selected) != 3 {
+		t.Fatalf("selected %v, want every version", m.selectedKeys())
+	}
+
+	next, cmd := m.downloadSelected()
+	m = next.(model)
+	msg, ok := cmd().(batchDownloadMsg)
+	if !ok {
+		t.Fatalf("got %#v, want a batch download", msg)
+	}
+	if len(msg.paths) != 2 || len(msg.failed) != 1 || !strings.HasPrefix(msg.failed[0], "bitnami/redis 19.0.0: ") {
+		t.Fatalf("got paths %v and failures %v, want two downloads and one failure", msg.paths, msg.failed)
+	}
+	// Parallel downloads are still reported in the order of their keys
+	if got := strings.Join(msg.keys, " "); got != "bitnami/redis@18.0.0 bitnami/redis@19.0.1" || !strings.Contains(msg.paths[0], "18.0.0") {
+		t.Errorf("got keys %s and paths %v, want them in order", got, msg.paths)
+	}
+
+	next, _ = m.Update(msg)
+	m = next.(model)
+	if !strings.Contains(m.message, "Downloaded 2 values files") || !strings.Contains(m.message, "1 failed") {
+		t.Errorf("message %q does not report the failure", m.message)
+	}
+	// The failed version stays selected so it can be retried
+	if !m.isSelected(versions[1]) || m.isSelected(versions[0]) || !m.downloaded[downloadKey("bitnami/redis", "19.0.1")] {
+		t.Errorf("selected %v, downloaded %v, want only the failed version left", m.selectedKeys(), m.downloaded)
+	}
+
+	// Deselecting removes the version again
+	m.state, m.cursor = stateVersionList, 1
+	if m = m.toggleSelected(); len(m.selected) != 0 {
+		t.Errorf("selected %v after toggling the failed version off", m.selectedKeys())
+	}
+}
+
+func TestWriteProvenance(t *testing.T) {
+	tests := []struct {
+		name     string
+		helm     fakeHelm
+		wantHelm string
+	}{
+		{
+			name:     "helm version",
+			helm:     fakeHelm{"version --short": "v3.14.2+gc309b6f\n"},
+			wantHelm: "v3.14.2+gc309b6f",
+		},
+		{
+			name:     "helm version fails",
+			helm:     fakeHelm{},
+			wantHelm: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.helm["show values --version 19.0.1 -- bitnami/redis"] = "replicaCount: 1\n"
+			useFakeHelm(t, tt.helm)
+			repo := HelmRepo{Name: "bitnami", URL: "https://charts.bitnami.com/bitnami"}
+			version := HelmVersion{Name: "bitnami/redis", Version: "19.0.1", AppVersion: "7.2.4"}
+			opts := options{outputDir: t.TempDir(), format: formatYAML, indent: 2, writeProvenance: true}
+
+			start := time.Now().UTC().Add(-time.Second)
+			done, ok := downloadValues(repo, version, opts)().(downloadCompleteMsg)
+			if !ok {
+				t.Fatal("the download failed")
+			}
+			if want := strings.TrimSuffix(done.path, ".yaml") + ".provenance.json"; provenancePath(done.path) != want {
+				t.Errorf("provenancePath = %s, want %s", provenancePath(done.path), want)
+			}
+
+			values, err := os.ReadFile(done.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(provenancePath(done.path))
+			if err != nil {
+				t.Fatalf("no provenance sidecar: %v", err)
+			}
+			var got provenance
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("sidecar %q: %v", data, err)
+			}
+
+			sum := sha256.Sum256(values)
+			want := provenance{
+				Repo:         "bitnami",
+				RepoURL:      "https://charts.bitnami.com/bitnami",
+				Chart:        "bitnami/redis",
+				Version:      "19.0.1",
+				AppVersion:   "7.2.4",
+				DownloadedAt: got.DownloadedAt,
+				HelmVersion:  tt.wantHelm,
+				ValuesSHA256: hex.EncodeToString(sum[:]),
+			}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if got.DownloadedAt.Before(start) || got.DownloadedAt.After(time.Now().UTC()) {
+				t.Errorf("downloaded_at %v is not the time of the download", got.DownloadedAt)
+			}
+		})
+	}
+
+	// --no-clobber keeps an existing sidecar as it keeps values files
+	useFakeHelm(t, fakeHelm{"show values --version 19.0.1 -- bitnami/redis": "replicaCount: 1\n"})
+	opts := options{outputDir: t.TempDir(), format: formatYAML, indent: 2, writeProvenance: true, noClobber: true}
+	version := HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}
+	path, err := valuesPath(HelmRepo{Name: "bitnami"}, version, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(provenancePath(path), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg, ok := downloadValues(HelmRepo{Name: "bitnami"}, version, opts)().(errorMsg); !ok || !strings.Contains(string(msg), "already exists") {
+		t.Errorf("got %#v, want the existing sidecar refused", msg)
+	}
+	if data, _ := os.ReadFile(provenancePath(path)); string(data) != "{}\n" {
+		t.Errorf("the sidecar was overwritten with %q", data)
+	}
+}
+
+func TestWatchRefresh(t *testing.T) {
+	old := []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}, {Name: "bitnami/redis", Version: "19.0.0"}}
+	refreshed := append([]HelmVersion{{Name: "bitnami/redis", Version: "19.1.0"}}, old...)
+	m := model{
+		state:       stateVersionList,
+		charts:      []HelmChart{{Name: "bitnami/redis"}},
+		versions:    old,
+		allVersions: old,
+		cursor:      1,
+		newVersions: make(map[string]bool),
+		downloaded:  make(map[string]bool),
+		rows:        make(rowCache),
+		opts:        options{watch: time.Minute},
+	}
+
+	// A refresh landing during a download leaves the list alone
+	m.loading, m.selectedVersion = true, 1
+	next, _ := m.updateWatchRefresh(watchRefreshMsg{chart: "bitnami/redis", versions: refreshed})
+	if got := next.(model); len(got.versions) != 2 || got.cursor != 1 || got.selectedVersion != 1 {
+		t.Fatalf("while loading: %d versions, cursor %d, selected %d", len(got.versions), got.cursor, got.selectedVersion)
+	}
+
+	// The download records the version it was started for
+	next, _ = m.Update(downloadCompleteMsg{version: old[1], path: "redis-19.0.0-values.yaml"})
+	if got := next.(model); !got.downloaded[downloadKey("bitnami/redis", "19.0.0")] || len(got.history) != 1 || got.history[0].version != old[1] {
+		t.Errorf("recorded %v and %+v, want 19.0.0", got.downloaded, got.history)
+	}
+
+	m.loading = false
+	next, _ = m.updateWatchRefresh(watchRefreshMsg{chart: "bitnami/redis", versions: refreshed})
+	m = next.(model)
+	if len(m.versions) != 3 || !m.newVersions[downloadKey("bitnami/redis", "19.1.0")] {
+		t.Fatalf("after the refresh: %d versions, new %v", len(m.versions), m.newVersions)
+	}
+	if m.versions[m.cursor].Version != "19.0.0" || m.versions[m.selectedVersion].Version != "19.0.0" {
+		t.Errorf("cursor on %s and selection on %s, want both kept on 19.0.0", m.versions[m.cursor].Version, m.versions[m.selectedVersion].Version)
+	}
+}
+
+func TestStartupProgress(t *testing.T) {
+	m := initialModel(options{}, config{})
+	if got := m.renderStartup(); !strings.Contains(got, "1/2 Updating repos…") || strings.Contains(got, "✓") {
+		t.Errorf("before the update: %q", got)
+	}
+
+	next, _ := m.Update(repoUpdateMsg{})
+	got := next.(model).renderStartup()
+	if !strings.Contains(got, "1/2 Updating repos ✓") || !strings.Contains(got, "2/2 Loading list…") {
+		t.Errorf("after the update: %q", got)
+	}
+
+	next, _ = m.Update(repoUpdateMsg{failed: true})
+	if got := next.(model).renderStartup(); !strings.Contains(got, "1/2 Updating repos ⚠️") {
+		t.Errorf("after a failed update: %q", got)
+	}
+}
+
+func TestHelmEnv(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"env": `HELM_BIN="helm"
+HELM_CACHE_HOME="/home/dev/.cache/helm"
+HELM_CONFIG_HOME="/home/dev/Library/Preferences/helm"
+HELM_REGISTRY_CONFIG="/home/dev/Library/Preferences/helm/registry/config.json"
+HELM_REPOSITORY_CACHE="/home/dev/.cache/helm/repository"
+HELM_REPOSITORY_CONFIG="/home/dev/Library/Preferences/helm/repositories.yaml"
+`,
+	})
+
+	env := helmEnvironment()
+	if env.RegistryConfig != "/home/dev/Library/Preferences/helm/registry/config.json" {
+		t.Errorf("RegistryConfig = %q", env.RegistryConfig)
+	}
+	if got := env.repositoryIndex("bitnami"); got != filepath.Join("/home/dev/.cache/helm/repository", "bitnami-index.yaml") {
+		t.Errorf("repositoryIndex = %q", got)
+	}
+	if got := (helmEnv{}).repositoryIndex("bitnami"); got != "" {
+		t.Errorf("repositoryIndex without a cache = %q, want none", got)
+	}
+}
+
+func TestToggleVersions(t *testing.T) {
+	charts := []HelmChart{{Name: "bitnami/nginx"}, {Name: "bitnami/redis"}}
+	versions := []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}, {Name: "bitnami/redis", Version: "19.0.0"}}
+	m := model{state: stateChartList, charts: charts, cursor: 1, chartCache: map[string][]HelmChart{}}
+	m = m.push()
+	m.state, m.selectedChart, m.versions, m.allVersions, m.cursor = stateVersionList, 1, versions, versions, 1
+
+	next, _ := m.toggleVersions()
+	m = next.(model)
+	if m.state != stateChartList || m.cursor != 1 || len(m.navStack) != 0 {
+		t.Fatalf("after V in the versions: state %v, cursor %d, %d frames", m.state, m.cursor, len(m.navStack))
+	}
+
+	next, cmd := m.toggleVersions()
+	m = next.(model)
+	if cmd != nil || m.state != stateVersionList || m.cursor != 1 || len(m.versions) != 2 {
+		t.Fatalf("after V in the charts: state %v, cursor %d, %d versions, reloaded %v", m.state, m.cursor, len(m.versions), cmd != nil)
+	}
+	if m, _ = m.back(); m.state != stateChartList {
+		t.Errorf("Esc went to %v, want the chart list", m.state)
+	}
+
+	// Another chart's versions are loaded as with Enter
+	m.cursor = 0
+	next, cmd = m.toggleVersions()
+	if m = next.(model); cmd == nil || !m.loading || m.cursor != 0 {
+		t.Errorf("V on another chart: loading %v, cursor %d", m.loading, m.cursor)
+	}
+}
+
+func TestWriteValuesProgress(t *testing.T) {
+	dir := t.TempDir()
+	values := []byte(strings.Repeat("key: value\n", 3*progressThreshold/10))
+	opts := options{outputDir: dir, format: formatYAML, indent: 2, show: showAll, writeProvenance: true}
+	useFakeHelm(t, fakeHelm{"version --short": "v3.14.0"})
+
+	var steps []string
+	msg := writeValues(HelmRepo{Name: "bitnami"}, HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}, values, opts)()
+	for {
+		progress, ok := msg.(progressMsg)
+		if !ok {
+			break
+		}
+		if progress.done == progress.total {
+			steps = append(steps, progress.step)
+		}
+		msg = waitForProgress(progress.ch)()
+	}
+
+	done, ok := msg.(downloadCompleteMsg)
+	if !ok {
+		t.Fatalf("got %#v, want the download to complete", msg)
+	}
+	if got := fmt.Sprint(steps); got != "[Writing Hashing]" {
+		t.Errorf("finished steps %s, want writing then hashing", got)
+	}
+	if data, err := os.ReadFile(done.path); err != nil || len(data) != len(values) {
+		t.Errorf("wrote %d bytes (%v), want %d", len(data), err, len(values))
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	dir := t.TempDir()
+	chart := HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}
+	broken := "image:\n  repository: redis\n tag: 7.2\n"
+
+	tests := []struct {
+		name     string
+		values   string
+		validate bool
+		wantErr  string
+	}{
+		{"valid", "image:\n  repository: redis\n", true, ""},
+		{"broken without --validate", broken, false, ""},
+		{"broken", broken, true, "line 2: did not find expected key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options{outputDir: filepath.Join(dir, tt.name), format: formatYAML, indent: 2, validate: tt.validate}
+			msg := saveValues(HelmRepo{Name: "bitnami"}, chart, []byte(tt.values), opts, nil)
+			errText, failed := msg.(errorMsg)
+			switch {
+			case tt.wantErr == "" && failed:
+				t.Errorf("unexpected error: %s", errText)
+			case tt.wantErr != "" && !strings.Contains(string(errText), tt.wantErr):
+				t.Errorf("got %#v, want an error mentioning %q", msg, tt.wantErr)
+			}
+			if _, err := os.Stat(opts.outputDir); failed && err == nil {
+				t.Error("values were written despite the error")
+			}
+		})
+	}
+}
+
+func TestRepoHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/good/index.yaml" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	repos := []HelmRepo{{Name: "gone", URL: server.URL + "/gone"}, {Name: "good", URL: server.URL + "/good/"}}
+	msg := checkRepos(repos, 2)()
+	for {
+		progress, ok := msg.(healthProgressMsg)
+		if !ok {
+			break
+		}
+		msg = waitForHealth(progress.ch)()
+	}
+	results, ok := msg.(healthDoneMsg)
+	if !ok || len(results) != 2 {
+		t.Fatalf("got %#v, want the results of both repositories", msg)
+	}
+
+	m := model{state: stateRepoList, allRepos: repos, repos: repos}
+	m = m.updateHealthDone(results)
+	if m.health[0].repo.Name != "gone" || m.health[0].err == nil || m.health[1].err != nil {
+		t.Errorf("health = %v, want gone unreachable first and good reachable", m.health)
+	}
+	if len(m.repos) != 2 {
+		t.Errorf("%d repositories listed before hiding, want 2", len(m.repos))
+	}
+
+	m.hideUnreachable = true
+	m = m.applyRepoView()
+	if len(m.repos) != 1 || m.repos[0].Name != "good" || m.hiddenUnreachable() != 1 {
+		t.Errorf("listed %v with unreachable hidden, want only good", m.repos)
+	}
+}
+
+func TestPinnedVersion(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"repo list -o json":                                       `[{"name":"bitnami","url":"https://charts.bitnami.com/bitnami"}]`,
+		"search repo -o json -- bitnami/":                         `[{"name":"bitnami/redis","version":"19.0.1"}]`,
+		"search repo --versions -o json --devel -- bitnami/redis": `[{"name":"bitnami/redis","version":"19.0.1"},{"name":"bitnami/redis","version":"19.0.0","app_version":"7.2.4"}]`,
+	})
+	path := filepath.Join(t.TempDir(), "config.json")
+	versions := []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}, {Name: "bitnami/redis", Version: "19.0.0"}}
+
+	m := model{state: stateVersionList, versions: versions, cursor: 1, opts: options{configPath: path}}
+	m, cmd := m.togglePin()
+	if msg, ok := cmd().(configSavedMsg); !ok || msg.err != nil {
+		t.Fatalf("got %#v, want the pin saved", msg)
+	}
+	if !m.isPinned(versions[1]) || m.isPinned(versions[0]) {
+		t.Errorf("pins = %v, want 19.0.0 pinned", m.cfg.Pins)
+	}
+
+	m.cursor = 0
+	if m = m.cursorToPin(); m.cursor != 1 {
+		t.Errorf("cursor = %d after loading, want the pinned version", m.cursor)
+	}
+
+	opts := options{repo: "bitnami", chart: "redis", printPath: true, outputDir: ".", format: formatYAML, configPath: path}
+	result, err := resolveAndDownload(opts, func(string, ...interface{}) {})
+	if err != nil || result.Version != "19.0.0" {
+		t.Errorf("got %+v (%v), want the pinned version without --version", result, err)
+	}
+
+	m.cursor = 1
+	if m, _ = m.togglePin(); m.cfg.Pins != nil {
+		t.Errorf("pins = %v after unpinning, want none", m.cfg.Pins)
+	}
+}
+
+func TestErrorHints(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    string
+		colors bool
+		want   string
+	}{
+		{"unknown error", "Failed to parse chart", false, ""},
+		{"plain", "Failed to get chart values: dial tcp: lookup charts.example.com: no such host", false,
+			"The repository could not be reached:\n  • check your network, or pass --proxy when you are behind a proxy\n  • press R in the repository list to see which repositories answer\n  • remove one that is gone with helm repo remove <name>"},
+		{"plain link", "This action needs the helm-diff plugin", false,
+			"Install the helm-diff plugin (https://github.com/databus23/helm-diff) with helm plugin install https://github.com/databus23/helm-diff, then restart helm-browser."},
+		{"clickable link", "This action needs the helm-diff plugin", true,
+			"Install the \x1b]8;;https://github.com/databus23/helm-diff\x1b\\"},
+	}
+
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderMarkdown(hintFor(tt.err), tt.colors)
+			if tt.colors && !strings.HasPrefix(got, tt.want) || !tt.colors && got != tt.want {
+				t.Errorf("renderMarkdown(hintFor(%q)) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveChart(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"search repo -o json -- redis":       `[{"name":"bitnami/redis","version":"19.0.1"},{"name":"bitnami/redis-cluster","version":"10.0.0"},{"name":"other/rediscommander","version":"1.0.0"}]`,
+		"search repo -o json -- nginx":       `[{"name":"other/nginx","version":"1.0.0"},{"name":"bitnami/nginx","version":"15.0.0"},{"name":"bitnami/nginx-ingress","version":"9.0.0"}]`,
+		"search repo -o json -- mongo":       `[{"name":"bitnami/mongodb","version":"15.0.0"},{"name":"bitnami/mongodb-sharded","version":"8.0.0"}]`,
+		"search repo -o json -- other/nginx": `[{"name":"other/nginx","version":"1.0.0"}]`,
+		"search repo -o json -- missing":     `[]`,
+		"search repo -o json -- bitnami/":    `[{"name":"bitnami/nginx","version":"15.0.0"},{"name":"bitnami/nginx-ingress","version":"9.0.0"}]`,
+	})
+
+	tests := []struct {
+		name    string
+		opts    options
+		want    string
+		wantErr string
+	}{
+		{"single exact name beats partial matches", options{chart: "redis"}, "bitnami/redis", ""},
+		{"several exact names are ambiguous", options{chart: "nginx"}, "", "matches 2 charts"},
+		{"first match among exact names", options{chart: "nginx", firstMatch: true}, "bitnami/nginx", ""},
+		{"several partial matches are ambiguous", options{chart: "mongo"}, "", "matches 2 charts"},
+		{"full name", options{chart: "other/nginx"}, "other/nginx", ""},
+		{"name within --repo", options{chart: "nginx", repo: "bitnami"}, "bitnami/nginx", ""},
+		{"no match", options{chart: "missing"}, "", "no chart matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chart, err := resolveChart(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %s (%v), want an error mentioning %q", chart.Name, err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "nginx-ingress") {
+					t.Errorf("partial matches listed next to exact ones: %v", err)
+				}
+				return
+			}
+			if err != nil || chart.Name != tt.want {
+				t.Errorf("got %s (%v), want %s", chart.Name, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExactChartMatch(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"search repo -o json -- redis":   `[{"name":"bitnami/redis","version":"19.0.1"},{"name":"bitnami/redis-cluster","version":"10.0.0"},{"name":"other/rediscommander","version":"1.0.0"}]`,
+		"search repo -o json -- redis-c": `[{"name":"bitnami/redis-cluster","version":"10.0.0"}]`,
+	})
+
+	chart, err := resolveChart(options{chart: "redis", exact: true})
+	if err != nil || chart.Name != "bitnami/redis" {
+		t.Errorf("got %s (%v) with --exact, want bitnami/redis", chart.Name, err)
+	}
+	if _, err := resolveChart(options{chart: "redis-c", exact: true}); err == nil || !strings.Contains(err.Error(), "(--exact)") {
+		t.Errorf("got %v with --exact and only partial matches, want no chart", err)
+	}
+
+	msg := searchAllCharts("redis", true)()
+	if charts, ok := msg.(searchResultsMsg); !ok || len(charts) != 1 || charts[0].Name != "bitnami/redis" {
+		t.Errorf("got %#v, want only bitnami/redis", msg)
+	}
+	if got := exactCharts([]HelmChart{{Name: "bitnami/redis"}, {Name: "other/redis"}}, "other/redis"); len(got) != 1 || got[0].Name != "other/redis" {
+		t.Errorf("exactCharts with a repository = %v, want other/redis", got)
+	}
+}
+
+func TestLibraryCharts(t *testing.T) {
+	useFakeHelm(t, fakeHelm{
+		"show chart -- bitnami/common": "name: common\ntype: library\n",
+		"show chart -- bitnami/redis":  "name: redis\n",
+	})
+	charts := []HelmChart{{Name: "bitnami/common"}, {Name: "bitnami/redis"}}
+	m := initialModel(options{concurrency: 2}, config{})
+	m.state, m.loading = stateChartList, false
+	m.repos = []HelmRepo{{Name: "bitnami"}}
+	m.chartCache["bitnami"] = charts
+	m.charts = charts
+	m.cursor = 1
+
+	next, cmd := m.toggleLibraryCharts()
+	m = next.(model)
+	if !m.checkingTypes || cmd == nil {
+		t.Fatal("hiding library charts did not start checking the chart types")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(model)
+	if len(m.charts) != 1 || m.charts[0].Name != "bitnami/redis" || m.cursor != 0 {
+		t.Errorf("charts = %v (cursor %d), want only bitnami/redis highlighted", m.charts, m.cursor)
+	}
+	if m.chartTypes["bitnami/redis"] != chartTypeApplication {
+		t.Errorf("type of bitnami/redis = %q, want %s by default", m.chartTypes["bitnami/redis"], chartTypeApplication)
+	}
+
+	next, _ = m.toggleLibraryCharts()
+	m = next.(model)
+	if len(m.charts) != 2 || m.charts[m.cursor].Name != "bitnami/redis" {
+		t.Errorf("charts = %v (cursor %d) shown again, want both with bitnami/redis highlighted", m.charts, m.cursor)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	oldRetries, oldDelay := commandRetries, retryDelay
+	commandRetries, retryDelay = 2, time.Millisecond
+	t.Cleanup(func() { commandRetries, retryDelay = oldRetries, oldDelay })
+
+	calls := map[string]int{}
+	useFakeHelm(t, helmFunc(func(args ...string) ([]byte, error) {
+		line := strings.Join(args, " ")
+		calls[line]++
+		switch {
+		case strings.Contains(line, "missing"):
+			return nil, errors.New("Error: chart \"missing\" not found")
+		case line == "show values --version 1.0.0 -- bitnami/redis" && calls[line] < 3:
+			return nil, errors.New("Error: connection reset by peer")
+		case line == "repo list -o json":
+			return nil, errors.New("Error: no repositories to show")
+		}
+		return []byte("replicaCount: 1\n"), nil
+	}))
+
+	tests := []struct {
+		args      []string
+		wantErr   bool
+		wantCalls int
+	}{
+		{[]string{"show", "values", "--version", "1.0.0", "--", "bitnami/redis"}, false, 3},
+		{[]string{"show", "values", "--version", "1.0.0", "--", "bitnami/missing"}, true, 1},
+		{[]string{"repo", "list", "-o", "json"}, true, 1},
+	}
+	for _, tt := range tests {
+		_, err := runHelm(context.Background(), tt.args...)
+		if (err != nil) != tt.wantErr || calls[strings.Join(tt.args, " ")] != tt.wantCalls {
+			t.Errorf("helm %v: err = %v after %d calls, want error %v after %d", tt.args, err, calls[strings.Join(tt.args, " ")], tt.wantErr, tt.wantCalls)
+		}
+	}
+}
+
+func TestFavoritesFirst(t *testing.T) {
+	repos := []HelmRepo{{Name: "argo"}, {Name: "bitnami"}, {Name: "jetstack"}}
+	m := model{state: stateRepoList, allRepos: repos, repos: repos, cursor: 2, width: 200, rows: make(rowCache), opts: options{configPath: filepath.Join(t.TempDir(), "config.json"), repoGrid: true}}
+	_ = m.repoGrid()
+
+	next, cmd := m.toggleFavorite()
+	m = next.(model)
+	if msg, ok := cmd().(configSavedMsg); !ok || msg.err != nil || !m.isFavorite("jetstack") {
+		t.Fatalf("got %#v, want jetstack saved as a favorite", msg)
+	}
+	if grid := m.repoGrid(); !strings.Contains(grid, "★") {
+		t.Errorf("the grid kept the cached row without the star:\n%s", grid)
+	}
+	if m.repos[0].Name != "argo" {
+		t.Errorf("repos = %v, want the helm order until favorites come first", m.repos)
+	}
+
+	next, _ = m.toggleFavoritesFirst()
+	m = next.(model)
+	if m.repos[0].Name != "jetstack" || m.cursor != 0 {
+		t.Errorf("repos = %v (cursor %d), want jetstack first and highlighted", m.repos, m.cursor)
+	}
+	if sep := m.favoritesSeparator(1, 0, func(i int) string { return m.repos[i].Name }); sep == "" {
+		t.Error("no separator between the favorites and the other repositories")
+	}
+
+	charts := []HelmChart{{Name: "bitnami/nginx"}, {Name: "bitnami/redis"}}
+	m.cfg.Favorites = append(m.cfg.Favorites, "bitnami/redis")
+	m.state, m.charts, m.cursor = stateChartList, charts, 0
+	m, _ = m.sortCharts()
+	if m.charts[0].Name != "bitnami/redis" || charts[0].Name != "bitnami/nginx" {
+		t.Errorf("charts = %v, want bitnami/redis first without changing the cached list", m.charts)
+	}
+}
+
+func TestSingleVersionHint(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     bool
+	}{
+		{[]string{"19.0.1"}, true},
+		{[]string{"0.1.0"}, false},
+		{[]string{"1.0.0"}, false},
+		{[]string{"19.0.1", "19.0.0"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		versions := make([]HelmVersion, len(tt.versions))
+		for i, v := range tt.versions {
+			versions[i] = HelmVersion{Name: "bitnami/redis", Version: v}
+		}
+		if got := singleVersionSuspicious(versions); got != tt.want {
+			t.Errorf("singleVersionSuspicious(%v) = %v, want %v", tt.versions, got, tt.want)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bitnami/redis" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"redis","available_versions":[{"version":"19.0.1","ts":1},{"version":"19.0.0","ts":0}]}`))
+	}))
+	defer server.Close()
+	old := artifactHubPackagesURL
+	artifactHubPackagesURL = server.URL
+	defer func() { artifactHubPackagesURL = old }()
+
+	m := model{allVersions: []HelmVersion{{Name: "bitnami/redis", Version: "19.0.1"}}, opts: options{artifactHub: true}}
+	m.hubVersions = loadHubVersions("bitnami/redis")().(hubVersionsMsg)
+	if hint := m.singleVersionHint(); !strings.Contains(hint, "ArtifactHub lists 2 versions: 19.0.1, 19.0.0") {
+		t.Errorf("hint = %q, want the versions ArtifactHub lists", hint)
+	}
+	if _, err := queryHubVersions("bitnami/missing"); err == nil {
+		t.Error("a chart unknown to ArtifactHub gave no error")
+	}
+}
+
+func TestValuesFilenames(t *testing.T) {
+	repo := HelmRepo{Name: "bitnami"}
+	chart := HelmVersion{Name: "bitnami/redis", Version: "19.0.1"}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"yaml", nil, []string{"redis-19.0.1-default-values.yaml", "redis-19.0.1-common-subchart-values.yaml", "redis-19.0.1-values-production.yml", "19.0.1.yaml"}},
+		{"json", []string{"--format", "json"}, []string{"redis-19.0.1-default-values.json", "redis-19.0.1-common-subchart-values.json", "redis-19.0.1-values-production.json", "19.0.1.json"}},
+		{"extension", []string{"--format", "flat", "--extension", "env"}, []string{"redis-19.0.1-default-values.env", "redis-19.0.1-common-subchart-values.env", "redis-19.0.1-values-production.env", "19.0.1.env"}},
+		{"yml extension", []string{"--extension", "yml"}, []string{"redis-19.0.1-default-values.yml", "redis-19.0.1-common-subchart-values.yml", "redis-19.0.1-values-production.yml", "19.0.1.yml"}},
+		{"template keeps yml", []string{"--filename-template", "{{.Chart}}.yml"}, []string{"redis.yml", "redis-19.0.1-common-subchart-values.yaml", "redis-19.0.1-values-production.yml", "19.0.1.yaml"}},
+		{"template with json", []string{"--filename-template", "{{.Chart}}.yaml", "--format", "json"}, []string{"redis.json", "redis-19.0.1-common-subchart-values.json", "redis-19.0.1-values-production.json", "19.0.1.json"}},
+		{"template without extension", []string{"--filename-template", "{{.Chart}}-{{.Version}}", "--extension", "env"}, []string{"redis-19.0.1.env", "redis-19.0.1-common-subchart-values.env", "redis-19.0.1-values-production.env", "19.0.1.env"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptions(tt.args, io.Discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path, err := valuesPath(repo, chart, opts)
+			if err != nil {
+				t.Fatalf("valuesPath: %v", err)
+			}
+			path = filepath.Base(path)
+			got := []string{path, valuesFilename(opts, "redis", "19.0.1", "common", "subchart-values"), bundledValuesPath(repo, chart, "values-production.yml", opts), archivePath("", "19.0.1", opts)}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseOptions([]string{"--extension", "../x"}, io.Discard); err == nil {
+		t.Error("--extension with a path was accepted")
+	}
+}
+
+func TestNoClobber(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    options
+		want    string
+		wantErr bool
+	}{
+		{"overwrites by default", options{}, "new\n", false},
+		{"no-clobber keeps the file", options{noClobber: true}, "old\n", true},
+		{"force overwrites under no-clobber", options{noClobber: true, force: true}, "new\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "redis-values.yaml")
+			if err := writeValuesFile(path, []byte("old\n"), tt.opts); err != nil {
+				t.Fatalf("first write: %v", err)
+			}
+			err := writeValuesFile(path, []byte("new\n"), tt.opts)
+			if tt.wantErr != (err != nil) || err != nil && !strings.Contains(err.Error(), "already exists") {
+				t.Errorf("second write: %v, want error %v", err, tt.wantErr)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("file holds %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
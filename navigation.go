@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// navFrame is a snapshot of a screen that esc returns to
+type navFrame struct {
+	state           state
+	cursor          int
+	selectedRepo    int
+	selectedChart   int
+	selectedVersion int
+	charts          []HelmChart
+	versions        []HelmVersion
+	searchQuery     string
+	favoritesFirst  bool
+}
+
+// push records the current screen before navigating away from it
+func (m model) push() model {
+	frame := navFrame{
+		state:           m.state,
+		cursor:          m.cursor,
+		selectedRepo:    m.selectedRepo,
+		selectedChart:   m.selectedChart,
+		selectedVersion: m.selectedVersion,
+		charts:          m.charts,
+		versions:        m.versions,
+		searchQuery:     m.searchQuery,
+		favoritesFirst:  m.favoritesFirst,
+	}
+	// Copy on append so earlier model values keep their own stack
+	m.navStack = append(m.navStack[:len(m.navStack):len(m.navStack)], frame)
+	return m
+}
+
+// back restores the most recently pushed screen. It reports false when
+// there is nowhere to go back to.
+func (m model) back() (model, bool) {
+	if len(m.navStack) == 0 {
+		return m, false
+	}
+
+	frame := m.navStack[len(m.navStack)-1]
+	m.navStack = m.navStack[:len(m.navStack)-1]
+
+	m.state = frame.state
+	m.cursor = frame.cursor
+	m.selectedRepo = frame.selectedRepo
+	m.selectedChart = frame.selectedChart
+	m.selectedVersion = frame.selectedVersion
+	m.charts = frame.charts
+	m.versions = frame.versions
+	m.latest = latestVersion(frame.versions)
+	m.searchQuery = frame.searchQuery
+	m.loading = false
+	m.menuOpen = false
+	m.preview = viewport{}
+	m.showingTabs = false
+
+	// Favorites first may have been switched since the list was left
+	if frame.favoritesFirst != m.favoritesFirst {
+		switch m.state {
+		case stateRepoList:
+			m = m.applyRepoView()
+		case stateChartList:
+			m, _ = m.resortCharts()
+		}
+	}
+	return m, true
+}
+
+// openTarget opens the repository given as the positional argument and, for
+// a repo/chart target, keeps the target pending until the charts are loaded.
+// An unknown repository leaves the normal repository list in place.
+func (m model) openTarget() (model, tea.Cmd) {
+	if isOCIRef(m.target) {
+		target := m.target
+		m.target = ""
+		return m.openOCI(target)
+	}
+	repoName, chartName, _ := strings.Cut(m.target, "/")
+	if i := repoIndex(m.repos, repoName); i >= 0 {
+		m.cursor = i
+		var cmd tea.Cmd
+		m, cmd = m.openRepo(i)
+		if chartName == "" {
+			m.target = ""
+			return m, cmd
+		}
+		// Charts are named after the repository as helm stored it
+		m.target = m.repos[i].Name + "/" + chartName
+		if !m.loading {
+			return m.openTargetChart()
+		}
+		return m, cmd
+	}
+
+	m.status = errorStyle.Render(fmt.Sprintf("⚠️  Repository '%s' not found", repoName))
+	m.target = ""
+	return m, nil
+}
+
+// openTargetChart opens the chart of a pending repo/chart target once the
+// chart list is loaded
+func (m model) openTargetChart() (model, tea.Cmd) {
+	target := m.target
+	m.target = ""
+	for i, chart := range m.charts {
+		if chart.Name == target {
+			m.cursor = i
+			return m.openChart(i)
+		}
+	}
+
+	m.status = errorStyle.Render(fmt.Sprintf("⚠️  Chart '%s' not found", target))
+	return m, nil
+}
+
+// openChart enters the version list of a chart
+func (m model) openChart(index int) (model, tea.Cmd) {
+	m = m.push()
+	m.selectedChart = index
+	m.cursor = 0
+	m.loading = true
+	m.state = stateVersionList
+	m.stats.viewChart(m.charts[index].Name)
+	m.minAppVersion = ""
+	return m, loadVersions(m.charts[index].Name, m.devel)
+}
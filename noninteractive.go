@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// downloadResult describes the values file written in non-interactive mode
+type downloadResult struct {
+	Repo    string `json:"repo"`
+	Chart   string `json:"chart"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Empty   bool   `json:"empty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// runNonInteractive resolves the chart and version given on the command line
+// and downloads its values without starting the TUI. It returns the exit code.
+// Progress goes to stderr unless --quiet is set; the result goes to stdout.
+func runNonInteractive(opts options, stdout, stderr io.Writer) int {
+	progress := func(format string, args ...interface{}) {
+		if !opts.quiet && !opts.json {
+			_, _ = fmt.Fprintf(stderr, format+"\n", args...)
+		}
+	}
+
+	result, err := resolveAndDownload(opts, progress)
+	if err != nil {
+		sessionLog.log(logEntry{Event: "error", Error: err.Error()})
+		if opts.json {
+			_ = json.NewEncoder(stderr).Encode(map[string]string{"error": err.Error()})
+		} else {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			if hint := hintFor(err.Error()); hint != "" {
+				_, _ = fmt.Fprintf(stderr, "Hint: %s\n", renderMarkdown(hint, false))
+			}
+		}
+		return 1
+	}
+
+	if opts.json {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+		return 0
+	}
+
+	if result.Empty && !opts.quiet {
+		_, _ = fmt.Fprintf(stderr, "Warning: the chart has empty default values, wrote an empty file\n")
+	}
+	if result.Warning != "" && !opts.quiet {
+		_, _ = fmt.Fprintln(stderr, result.Warning)
+	}
+	_, _ = fmt.Fprintln(stdout, result.Path)
+	return 0
+}
+
+// resolveAndDownload performs the repo → chart → version → download flow
+// by running the same commands the TUI uses, one after another. Without
+// --version the version pinned in the config file is used, or else the
+// latest version as reported by the chart search.
+func resolveAndDownload(opts options, progress func(format string, args ...interface{})) (downloadResult, error) {
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		return downloadResult{}, err
+	}
+
+	progress("Loading repositories...")
+	repos, err := runRepos()
+	if err != nil {
+		return downloadResult{}, err
+	}
+
+	progress("Resolving chart %q...", opts.chart)
+	chart, err := resolveChart(opts)
+	if err != nil {
+		return downloadResult{}, err
+	}
+
+	version := HelmVersion{Name: chart.Name, Version: chart.Version, AppVersion: chart.AppVersion}
+	if pinned, ok := cfg.pinnedVersion(chart.Name); ok && opts.version == "" {
+		progress("Using the pinned version %s", pinned)
+		version, err = resolveVersion(chart.Name, pinned, true)
+		if err != nil {
+			return downloadResult{}, fmt.Errorf("pinned version: %w", err)
+		}
+	} else if opts.version == "" {
+		progress("Using the latest version %s", chart.Version)
+	} else {
+		version, err = resolveVersion(chart.Name, opts.version, opts.devel)
+		if err != nil {
+			return downloadResult{}, err
+		}
+	}
+
+	repo := repoForChart(repos, chart.Name)
+
+	if opts.printPath {
+		path, err := valuesPath(repo, version, opts)
+		if err != nil {
+			return downloadResult{}, err
+		}
+		return downloadResult{Repo: repo.Name, Chart: version.Name, Version: version.Version, Path: path}, nil
+	}
+
+	progress("Downloading values for %s %s...", version.Name, version.Version)
+	switch msg := downloadValues(repo, version, opts)().(type) {
+	case downloadCompleteMsg:
+		return downloadResult{
+			Repo:    repo.Name,
+			Chart:   version.Name,
+			Version: version.Version,
+			Path:    msg.path,
+			Empty:   msg.empty,
+			Warning: msg.warning,
+		}, nil
+	case errorMsg:
+		return downloadResult{}, fmt.Errorf("%s", msg)
+	default:
+		return downloadResult{}, fmt.Errorf("unexpected result %T", msg)
+	}
+}
+
+// runRepos loads the configured repositories synchronously
+func runRepos() ([]HelmRepo, error) {
+	switch msg := loadRepos()().(type) {
+	case reposLoadedMsg:
+		return msg, nil
+	case errorMsg:
+		return nil, fmt.Errorf("%s", msg)
+	default:
+		return nil, fmt.Errorf("unexpected result %T", msg)
+	}
+}
+
+// resolveChart finds the single chart selected by --repo and --chart
+func resolveChart(opts options) (HelmChart, error) {
+	cmd := searchCharts(opts.chart)
+	if opts.repo != "" {
+		cmd = loadCharts(opts.repo)
+	}
+
+	charts, err := runCharts(cmd)
+	if err != nil {
+		return HelmChart{}, err
+	}
+	if opts.exact {
+		charts = exactCharts(charts, opts.chart)
+	}
+
+	var candidates []HelmChart
+	for _, chart := range charts {
+		if chart.Name == opts.chart || opts.repo != "" && strings.HasSuffix(chart.Name, "/"+opts.chart) {
+			return chart, nil
+		}
+		if strings.Contains(chart.Name, opts.chart) {
+			candidates = append(candidates, chart)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	// A chart named exactly like the term wins over charts that merely
+	// contain it, so redis picks bitnami/redis over bitnami/redis-cluster
+	if exact := exactCharts(candidates, opts.chart); len(exact) > 0 {
+		candidates = exact
+	}
+
+	switch {
+	case len(candidates) == 0 && opts.exact:
+		return HelmChart{}, fmt.Errorf("no chart is named %q (--exact)", opts.chart)
+	case len(candidates) == 0:
+		return HelmChart{}, fmt.Errorf("no chart matches %q", opts.chart)
+	case len(candidates) == 1 || opts.firstMatch || opts.yes:
+		return candidates[0], nil
+	}
+
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.Name
+	}
+	return HelmChart{}, fmt.Errorf("%q matches %d charts, pass the full name or --first-match:\n  %s",
+		opts.chart, len(candidates), strings.Join(names, "\n  "))
+}
+
+// resolveVersion finds the requested version of a chart
+func resolveVersion(chartName, want string, devel bool) (HelmVersion, error) {
+	versions, err := runVersions(chartName, devel)
+	if err != nil {
+		return HelmVersion{}, err
+	}
+
+	for _, v := range versions {
+		if v.Version == want {
+			return v, nil
+		}
+	}
+	return HelmVersion{}, fmt.Errorf("chart %s has no version %q", chartName, want)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ociScheme prefixes chart references stored in an OCI registry
+const ociScheme = "oci://"
+
+// ociClient talks to OCI registries; tests replace it to trust their server
+var ociClient = &http.Client{Timeout: 30 * time.Second, Transport: newTransport(nil)}
+
+// errRegistryAuth means the registry refused to list tags without credentials
+var errRegistryAuth = errors.New("registry requires authentication")
+
+// errForeignPage means the registry's next page points at another host, where
+// the registry's credentials must not be sent
+var errForeignPage = errors.New("registry sent a next page on another host")
+
+// isOCIRef reports whether a chart name is an oci:// reference
+func isOCIRef(name string) bool {
+	return strings.HasPrefix(name, ociScheme)
+}
+
+// splitOCIRef returns the registry host and repository path of an oci://
+// reference, e.g. ghcr.io and org/charts/app
+func splitOCIRef(ref string) (host, repository string, err error) {
+	host, repository, _ = strings.Cut(strings.TrimPrefix(ref, ociScheme), "/")
+	repository = strings.Trim(repository, "/")
+	if host == "" || repository == "" {
+		return "", "", fmt.Errorf("invalid OCI reference %q, expected oci://registry/path/chart", ref)
+	}
+	return host, repository, nil
+}
+
+// ociRepo returns the registry of an oci:// reference as a repository, so
+// --nest-by-repo and the filename template have a name to use
+func ociRepo(ref string) HelmRepo {
+	host, _, _ := splitOCIRef(ref)
+	return HelmRepo{Name: host, URL: ref}
+}
+
+// loadOCITags lists the tags of a chart in an OCI registry, following the
+// registry's pagination, and returns them as versions newest first
+func loadOCITags(ref string, devel bool) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := listOCITags(ref)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to list OCI tags: %v", err))
+		}
+		return versionsLoadedMsg(ociVersions(ref, tags, devel))
+	}
+}
+
+// ociVersions keeps the tags that are chart versions and sorts them by
+// semantic version, newest first. OCI tags cannot contain "+", so helm
+// pushes build metadata with "_" instead.
+func ociVersions(ref string, tags []string, devel bool) []HelmVersion {
+	var versions []HelmVersion
+	for _, tag := range tags {
+		version := strings.ReplaceAll(tag, "_", "+")
+		if !parseSemver(version).valid || !devel && isPrerelease(version) {
+			continue
+		}
+		versions = append(versions, HelmVersion{Name: ref, Version: version})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) > 0
+	})
+	return versions
+}
+
+// listOCITags fetches every page of the registry's tag list
+func listOCITags(ref string) ([]string, error) {
+	host, repository, err := splitOCIRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	base := "https://" + host
+	next := base + "/v2/" + repository + "/tags/list?n=100"
+	auth := ""
+	var tags []string
+	for next != "" {
+		resp, err := ociGet(next, auth)
+		if err != nil {
+			return nil, err
+		}
+
+		// Anonymous pulls from most public registries still need a token
+		if resp.StatusCode == http.StatusUnauthorized && auth == "" {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			_ = resp.Body.Close()
+			if auth, err = ociAuthorize(host, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			err = fmt.Errorf("%w (HTTP %d), run: helm registry login %s", errRegistryAuth, resp.StatusCode, host)
+		case http.StatusNotFound:
+			err = fmt.Errorf("chart %s not found in the registry", repository)
+		default:
+			err = fmt.Errorf("registry returned %s", resp.Status)
+		}
+		link := resp.Header.Get("Link")
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, page.Tags...)
+		if next, err = nextPageURL(base, link); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// ociGet performs a GET with an optional Authorization header
+func ociGet(target, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(appContext, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return ociClient.Do(req)
+}
+
+// nextPageURL returns the next page from a Link header such as
+// </v2/app/tags/list?last=1.2.0&n=100>; rel="next", or "" on the last page.
+// Pages on another scheme or host are refused, since the registry's
+// Authorization header would follow them.
+func nextPageURL(base, link string) (string, error) {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		u, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		next, err := u.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("registry sent an invalid next page %q: %w", target, err)
+		}
+		if next.Scheme != u.Scheme || next.Host != u.Host {
+			return "", fmt.Errorf("%w: %s", errForeignPage, next.Redacted())
+		}
+		return next.String(), nil
+	}
+	return "", nil
+}
+
+// ociAuthorize answers a WWW-Authenticate challenge, using the credentials
+// from helm registry login when there are any. Bearer challenges are
+// exchanged for a token; Basic challenges need the stored credentials.
+func ociAuthorize(host, challenge string) (string, error) {
+	basic := registryCredentials(host)
+	scheme, params, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if basic == "" {
+			return "", fmt.Errorf("%w, run: helm registry login %s", errRegistryAuth, host)
+		}
+		return "Basic " + basic, nil
+
+	case "bearer":
+		fields := parseChallenge(params)
+		realm, err := url.Parse(fields["realm"])
+		if err != nil || fields["realm"] == "" {
+			return "", fmt.Errorf("registry sent an invalid authentication challenge: %q", challenge)
+		}
+		query := realm.Query()
+		for _, key := range []string{"service", "scope"} {
+			if fields[key] != "" {
+				query.Set(key, fields[key])
+			}
+		}
+		realm.RawQuery = query.Encode()
+
+		auth := ""
+		if basic != "" {
+			auth = "Basic " + basic
+		}
+		resp, err := ociGet(realm.String(), auth)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%w (token request returned %s), run: helm registry login %s", errRegistryAuth, resp.Status, host)
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("failed to parse registry token: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+
+	return "", fmt.Errorf("%w, run: helm registry login %s", errRegistryAuth, host)
+}
+
+// parseChallenge splits the parameters of a WWW-Authenticate header, e.g.
+// realm="https://ghcr.io/token",service="ghcr.io"
+func parseChallenge(params string) map[string]string {
+	fields := map[string]string{}
+	for params != "" {
+		var key, value string
+		key, params, _ = strings.Cut(params, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(params, `"`) {
+			value, params, _ = strings.Cut(params[1:], `"`)
+			params = strings.TrimPrefix(strings.TrimSpace(params), ",")
+		} else {
+			value, params, _ = strings.Cut(params, ",")
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// registryCredentials returns the base64 user:password stored by helm
+// registry login for a host, or "" when there are none
+func registryCredentials(host string) string {
+	path := helmEnvironment().RegistryConfig
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	var cfg struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return ""
+	}
+	entry, ok := cfg.Auths[host]
+	switch {
+	case !ok:
+		return ""
+	case entry.Auth != "":
+		return entry.Auth
+	case entry.Username != "":
+		return base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password))
+	}
+	return ""
+}
+
+// openOCI opens the version list of a chart in an OCI registry
+func (m model) openOCI(ref string) (model, tea.Cmd) {
+	if _, _, err := splitOCIRef(ref); err != nil {
+		m.status = errorStyle.Render("⚠️  " + err.Error())
+		return m, nil
+	}
+	m.charts = []HelmChart{{Name: ref}}
+	m.searchQuery = ""
+	return m.openChart(0)
+}
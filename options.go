@@ -0,0 +1,260 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultConcurrency is the worker-pool size used when --concurrency is not given
+const defaultConcurrency = 4
+
+// options holds the command-line configuration for a session
+type options struct {
+	concurrency      int
+	helmTimeout      time.Duration
+	retries          int
+	configPath       string
+	logFile          string
+	groupRepos       bool
+	repoGrid         bool
+	noPaging         bool
+	gutter           bool
+	artifactHub      bool
+	proxy            *url.URL
+	sortRepos        string
+	sortCharts       string
+	columns          []string
+	prefetch         bool
+	noColor          bool
+	updateRepos      []string
+	updateBestEffort bool
+	writeProvenance  bool
+	noConfirmQuit    bool
+	noPrompts        bool
+	devel            bool
+	watch            time.Duration
+	idleTimeout      time.Duration
+	latestBadge      string
+	kubeVersion      string
+	stripComments    bool
+	minify           bool
+	validate         bool
+	sourceHeader     bool
+	archiveDedupe    bool
+	indent           int
+	outputDir        string
+	override         bool
+	nestByRepo       bool
+	noClobber        bool
+	force            bool
+	format           string
+	show             string
+	filenameTemplate string
+	extension        string
+	exportFormat     string
+
+	// Non-interactive selection
+	repo       string
+	chart      string
+	version    string
+	yes        bool
+	firstMatch bool
+	exact      bool
+	quiet      bool
+	json       bool
+	printPath  bool
+
+	// target is the optional positional argument: a repo or repo/chart to open
+	target string
+}
+
+// parseOptions parses the command-line arguments into options
+func parseOptions(args []string, output io.Writer) (options, error) {
+	var opts options
+
+	fs := flag.NewFlagSet("helm-browser", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&opts.configPath, "config", defaultConfigPath(), "path to the configuration file")
+	fs.StringVar(&opts.logFile, "log-file", "", "append a JSON log of every helm command, its duration and outcome to this file")
+	fs.BoolVar(&opts.groupRepos, "group-repos", false, "group repositories into the sections defined in the config file")
+	fs.BoolVar(&opts.repoGrid, "repo-grid", false, "flow the repository list into two or three columns when the terminal is wide enough")
+	fs.BoolVar(&opts.noPaging, "no-paging", false, "show lists as one continuous scrolling list instead of pages of ten")
+	fs.BoolVar(&opts.gutter, "gutter", false, "show the highlighted item's position in the whole list, e.g. 47/213, in a column left of the list")
+	fs.BoolVar(&opts.artifactHub, "artifacthub", false, "enable searching ArtifactHub for repositories to add (A in the repository list); queries artifacthub.io")
+	fs.Func("proxy", "proxy URL for ArtifactHub and OCI registry requests, overriding HTTP_PROXY/HTTPS_PROXY", func(value string) error {
+		proxy, err := parseProxy(value)
+		opts.proxy = proxy
+		return err
+	})
+	fs.StringVar(&opts.sortRepos, "sort-repos", sortHelm, "repository order: helm (as configured), name or url (by host)")
+	fs.StringVar(&opts.sortCharts, "sort-charts", chartSortName, "chart order: name or versions (most versions first)")
+	fs.Func("columns", "comma-separated helm search repo fields to show as extra chart list columns, e.g. description", func(value string) error {
+		opts.columns = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.columns = append(opts.columns, name)
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&opts.prefetch, "prefetch", false, "load every repository's chart list in the background after startup")
+	fs.BoolVar(&opts.noColor, "no-color", false, "disable colours and syntax highlighting (also set by NO_COLOR)")
+	fs.Func("update-repos", "comma-separated repositories to update at startup instead of all", func(value string) error {
+		opts.updateRepos = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.updateRepos = append(opts.updateRepos, name)
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&opts.updateBestEffort, "update-best-effort", false, "continue with the cached repository data when helm repo update fails")
+	fs.BoolVar(&opts.noConfirmQuit, "no-confirm-quit", false, "quit without asking when selected versions have not been downloaded")
+	fs.BoolVar(&opts.noPrompts, "no-prompts", false, "skip confirmations whose answer is safe to assume; with --force, skip the destructive ones too")
+	fs.DurationVar(&opts.watch, "watch", 0, "refresh the open version list at this interval (e.g. 10m) and mark new versions; 0 disables")
+	fs.DurationVar(&opts.idleTimeout, "idle-timeout", 0, "quit after this long (e.g. 15m) without a key press; 0 disables")
+	fs.StringVar(&opts.latestBadge, "latest-badge", "🏷️  LATEST", "text of the badge on the latest version; empty hides it")
+	fs.StringVar(&opts.kubeVersion, "kube-version", "", "Kubernetes version (e.g. 1.28) to check each chart version's kubeVersion against, or \"cluster\" to ask kubectl")
+	fs.BoolVar(&opts.devel, "devel", false, "include development versions (helm search repo --devel); D toggles it in the version list")
+	fs.DurationVar(&opts.helmTimeout, "helm-timeout", defaultHelmTimeout, "how long a single helm command may run before it is stopped")
+	fs.IntVar(&opts.retries, "retries", defaultRetries, "how many times a helm command that reaches the network is retried after failing, waiting 1s, 2s, 4s... in between (0 disables retries)")
+	fs.IntVar(&opts.concurrency, "concurrency", defaultConcurrency, "maximum number of helm commands run in parallel by background operations")
+	fs.StringVar(&opts.repo, "repo", "", "repository to search in non-interactive mode")
+	fs.StringVar(&opts.chart, "chart", "", "chart to download without the TUI (enables non-interactive mode)")
+	fs.StringVar(&opts.version, "version", "", "chart version to download in non-interactive mode")
+	fs.BoolVar(&opts.yes, "yes", false, "assume yes for confirmations, picking the first match when --chart is ambiguous")
+	fs.BoolVar(&opts.firstMatch, "first-match", false, "pick the first matching chart (sorted by name) when --chart is ambiguous")
+	fs.BoolVar(&opts.exact, "exact", false, "only match charts whose name equals the search term or --chart, not charts that merely contain it")
+	fs.BoolVar(&opts.quiet, "quiet", false, "print only the result or error in non-interactive mode")
+	fs.BoolVar(&opts.json, "json", false, "print the non-interactive result as JSON")
+	fs.BoolVar(&opts.stripComments, "strip-comments", false, "remove comments from downloaded values, keeping only the data")
+	fs.BoolVar(&opts.minify, "minify", false, "drop keys whose values are null or empty maps/lists, keeping only defaults that are set (changes what the file means)")
+	fs.BoolVar(&opts.validate, "validate", false, "refuse to write values that do not parse as YAML, reporting the line where they break")
+	fs.IntVar(&opts.indent, "indent", 2, "spaces per indentation level when values are re-emitted (2-9)")
+	fs.StringVar(&opts.format, "format", formatYAML, "format of downloaded values: yaml, json, or flat for key.subkey=value lines")
+	fs.StringVar(&opts.show, "show", showValues, "what to download: values (helm show values) or all (helm show all: Chart.yaml, values, README and CRDs)")
+	fs.BoolVar(&opts.override, "override", false, "ask for common overrides such as replicaCount and image.tag before writing downloaded values")
+	fs.StringVar(&opts.outputDir, "output-dir", "", "directory values files are written to (default: the current directory)")
+	fs.BoolVar(&opts.noClobber, "no-clobber", false, "fail instead of overwriting a values file that already exists")
+	fs.BoolVar(&opts.force, "force", false, "go ahead where helm-browser would otherwise refuse or ask: replace existing values files even with --no-clobber and existing export files, and with --no-prompts also skip destructive confirmations")
+	fs.BoolVar(&opts.nestByRepo, "nest-by-repo", false, "write values files into a subdirectory named after the repository")
+	fs.StringVar(&opts.filenameTemplate, "filename-template", defaultFilenameTemplate, "Go template for values file names, with .Repo, .Name, .Chart, .Version and .AppVersion")
+	fs.StringVar(&opts.extension, "extension", "", "extension of values files in place of the one for --format (.yaml, .json or .txt for flat), e.g. yml")
+	fs.StringVar(&opts.exportFormat, "export-format", exportCSV, "format the version list is exported in with W: csv or json")
+	fs.BoolVar(&opts.printPath, "print-path", false, "resolve --chart and --version and print the values path without downloading")
+	fs.BoolVar(&opts.sourceHeader, "source-header", false, "start downloaded values with a comment naming the chart, version, repository and download time")
+	fs.BoolVar(&opts.archiveDedupe, "archive-dedupe", false, "when archiving all versions (A), skip versions whose values are identical to the previous version's")
+	fs.BoolVar(&opts.writeProvenance, "write-provenance", false, "write a JSON provenance sidecar next to each downloaded values file")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		opts.target = strings.Trim(fs.Arg(0), "/")
+	default:
+		return opts, fmt.Errorf("expected at most one repo or repo/chart argument, got %d", fs.NArg())
+	}
+
+	if opts.concurrency < 1 {
+		return opts, fmt.Errorf("--concurrency must be at least 1, got %d", opts.concurrency)
+	}
+
+	switch opts.sortRepos {
+	case sortHelm, sortName, sortURL:
+	default:
+		return opts, fmt.Errorf("--sort-repos must be one of helm, name or url, got %q", opts.sortRepos)
+	}
+
+	if opts.sortCharts != chartSortName && opts.sortCharts != chartSortVersions {
+		return opts, fmt.Errorf("--sort-charts must be name or versions, got %q", opts.sortCharts)
+	}
+
+	if opts.format != formatYAML && opts.format != formatFlat && opts.format != formatJSON {
+		return opts, fmt.Errorf("--format must be yaml, flat or json, got %q", opts.format)
+	}
+
+	if opts.exportFormat != exportCSV && opts.exportFormat != exportJSON {
+		return opts, fmt.Errorf("--export-format must be csv or json, got %q", opts.exportFormat)
+	}
+
+	switch {
+	case opts.show != showValues && opts.show != showAll:
+		return opts, fmt.Errorf("--show must be values or all, got %q", opts.show)
+	case opts.show == showAll && (opts.format != formatYAML || opts.stripComments || opts.minify || opts.override || opts.validate):
+		return opts, fmt.Errorf("--show all writes helm's output as is and cannot be combined with --format, --strip-comments, --minify, --override or --validate")
+	}
+
+	if opts.noPaging && opts.repoGrid {
+		return opts, fmt.Errorf("--no-paging cannot be combined with --repo-grid")
+	}
+
+	if opts.sourceHeader && opts.format == formatJSON {
+		return opts, fmt.Errorf("--source-header cannot be combined with --format json, which has no comments")
+	}
+
+	if opts.watch < 0 || opts.watch > 0 && opts.watch < 10*time.Second {
+		return opts, fmt.Errorf("--watch must be at least 10s, got %s", opts.watch)
+	}
+
+	if opts.idleTimeout < 0 || opts.idleTimeout > 0 && opts.idleTimeout < time.Second {
+		return opts, fmt.Errorf("--idle-timeout must be at least 1s, got %s", opts.idleTimeout)
+	}
+
+	if v := opts.kubeVersion; v != "" && v != kubeVersionCluster && !parseSemver(kubeCoreVersion(v)).valid {
+		return opts, fmt.Errorf("--kube-version must be a version such as 1.28 or \"cluster\", got %q", v)
+	}
+
+	if opts.helmTimeout <= 0 {
+		return opts, fmt.Errorf("--helm-timeout must be positive, got %s", opts.helmTimeout)
+	}
+
+	if opts.retries < 0 {
+		return opts, fmt.Errorf("--retries must not be negative, got %d", opts.retries)
+	}
+
+	if opts.indent < 2 || opts.indent > 9 {
+		return opts, fmt.Errorf("--indent must be between 2 and 9, got %d", opts.indent)
+	}
+
+	if opts.repo != "" && opts.chart == "" {
+		return opts, fmt.Errorf("--repo requires --chart")
+	}
+
+	if (opts.quiet || opts.json) && opts.chart == "" && !stdinPiped() {
+		return opts, fmt.Errorf("--quiet and --json require --chart or charts on stdin")
+	}
+
+	if opts.printPath && opts.chart == "" {
+		return opts, fmt.Errorf("--print-path requires --chart")
+	}
+
+	ext, err := normalizeExtension(opts.extension)
+	if err != nil {
+		return opts, fmt.Errorf("invalid --extension: %w", err)
+	}
+	opts.extension = ext
+
+	if _, err := parseFilenameTemplate(opts.filenameTemplate); err != nil {
+		return opts, fmt.Errorf("invalid --filename-template: %w", err)
+	}
+
+	return opts, nil
+}
+
+// colors reports whether output may be coloured, honouring --no-color and NO_COLOR
+func (o options) colors() bool {
+	return !o.noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// nonInteractive reports whether the options request a download without the TUI
+func (o options) nonInteractive() bool {
+	return o.chart != ""
+}
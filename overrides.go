@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overrideFields are the commonly customised values offered by --override,
+// in the order they are asked. Only those present in a chart's values are asked.
+var overrideFields = []string{
+	"replicaCount",
+	"image.registry",
+	"image.repository",
+	"image.tag",
+	"image.pullPolicy",
+	"service.type",
+	"service.port",
+	"ingress.enabled",
+	"ingress.hostname",
+	"persistence.enabled",
+	"persistence.size",
+}
+
+// overrideSession tracks the override prompts for one download
+type overrideSession struct {
+	repo    HelmRepo
+	version HelmVersion
+	values  []byte
+	fields  []string
+	current []string
+	index   int
+}
+
+// overrideValuesMsg carries the values of a chart version to be overridden
+type overrideValuesMsg struct {
+	values  []byte
+	fields  []string
+	current []string
+}
+
+// loadOverrideValues fetches the values of a chart version and finds the
+// fields that can be overridden
+func loadOverrideValues(chart HelmVersion) tea.Cmd {
+	return func() tea.Msg {
+		values, err := fetchValues(chart.Name, chart.Version)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to get chart values: %v", err))
+		}
+
+		doc, err := parseYAML(values)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to parse chart values: %v", err))
+		}
+
+		msg := overrideValuesMsg{values: values}
+		for _, field := range overrideFields {
+			if node := lookupPath(doc, field); node != nil && node.kind == yamlScalar && node.style != styleLiteral {
+				msg.fields = append(msg.fields, field)
+				msg.current = append(msg.current, scalarYAML(node, 0, 2))
+			}
+		}
+		return msg
+	}
+}
+
+// lookupPath finds the node at a dotted path such as image.tag
+func lookupPath(doc *yamlNode, path string) *yamlNode {
+	node := doc
+	for _, key := range strings.Split(path, ".") {
+		node = node.get(key)
+	}
+	return node
+}
+
+// applyOverride replaces the value at path with answer, which is read as a
+// YAML scalar so numbers and booleans keep their type. Only the value on the
+// key's line changes; the rest of the file, including comments, is kept.
+func applyOverride(values []byte, path, answer string) ([]byte, error) {
+	parsed, err := parseYAML([]byte("value: " + answer))
+	if err != nil || parsed.get("value") == nil || parsed.get("value").kind != yamlScalar {
+		return nil, fmt.Errorf("%q is not a single value", answer)
+	}
+
+	doc, err := parseYAML(values)
+	if err != nil {
+		return nil, err
+	}
+	node := lookupPath(doc, path)
+	if node == nil || node.kind != yamlScalar || node.style == styleLiteral {
+		return nil, fmt.Errorf("%s cannot be overridden", path)
+	}
+
+	// The value must sit on one line for it to be replaced in place
+	lines := strings.Split(string(values), "\n")
+	line := lines[node.line-1]
+	start := node.col - 1
+	current := stripComment(line[start:])
+	reread, err := parseYAML([]byte("value: " + current))
+	if err != nil || reread.get("value") == nil || reread.get("value").value != node.value {
+		return nil, fmt.Errorf("%s is not on a single line", path)
+	}
+
+	lines[node.line-1] = line[:start] + strings.TrimSpace(answer) + line[start+len(current):]
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// startOverrides opens the prompt for the first field, or writes the values
+// straight away when the chart has none of the common fields
+func (m model) startOverrides(msg overrideValuesMsg) (tea.Model, tea.Cmd) {
+	version := m.versions[m.selectedVersion]
+	repo := m.repoFor(version.Name)
+	if len(msg.fields) == 0 {
+		m.activity = "⬇️  No common fields to override, downloading values.yaml..."
+		return m, writeValues(repo, version, msg.values, m.opts)
+	}
+
+	m.loading = false
+	m.activity = fmt.Sprintf("✏️  Customising values of %s %s (Enter keeps the current value)", version.Name, version.Version)
+	m.override = overrideSession{repo: repo, version: version, values: msg.values, fields: msg.fields, current: msg.current}
+	return m.promptOverride(), nil
+}
+
+// promptOverride asks for the field at the current index
+func (m model) promptOverride() model {
+	o := m.override
+	m = m.openInput(inputOverride, fmt.Sprintf("✏️  %s (%d/%d):", o.fields[o.index], o.index+1, len(o.fields)))
+	m.input.value = o.current[o.index]
+	return m
+}
+
+// submitOverride applies one answer and moves on to the next field, writing
+// the customised values after the last one
+func (m model) submitOverride(answer string) (tea.Model, tea.Cmd) {
+	o := &m.override
+	if answer != "" && answer != o.current[o.index] {
+		values, err := applyOverride(o.values, o.fields[o.index], answer)
+		if err != nil {
+			m.input.err = err.Error()
+			return m, nil
+		}
+		o.values = values
+	}
+
+	o.index++
+	if o.index < len(o.fields) {
+		return m.promptOverride(), nil
+	}
+
+	m.input = inputPrompt{}
+	m.loading = true
+	m.activity = "⬇️  Writing customised values..."
+	cmd := writeValues(o.repo, o.version, o.values, m.opts)
+	m.override = overrideSession{}
+	return m, cmd
+}
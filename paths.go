@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultFilenameTemplate reproduces the original chart-version-default-values.yaml name
+const defaultFilenameTemplate = "{{.Chart}}-{{.Version}}-default-values.yaml"
+
+// showAllFilenameTemplate replaces the default name for --show all, whose
+// output includes the README and is not a values file
+const showAllFilenameTemplate = "{{.Chart}}-{{.Version}}-show-all.txt"
+
+// filenameData is the data available to --filename-template and to the
+// commands of custom actions
+type filenameData struct {
+	Repo       string
+	Name       string
+	Chart      string
+	Version    string
+	AppVersion string
+}
+
+// templateData describes a chart version for a template
+func templateData(repo HelmRepo, chart HelmVersion) filenameData {
+	chartParts := strings.Split(chart.Name, "/")
+	return filenameData{
+		Repo:       repo.Name,
+		Name:       chart.Name,
+		Chart:      chartParts[len(chartParts)-1],
+		Version:    chart.Version,
+		AppVersion: chart.AppVersion,
+	}
+}
+
+// parseFilenameTemplate parses a --filename-template value and checks that it
+// only refers to known fields
+func parseFilenameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("filename").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, filenameData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// valuesExtension returns the extension of values files: --extension, or
+// else the one of the --format
+func valuesExtension(opts options) string {
+	if opts.extension != "" {
+		return opts.extension
+	}
+	if ext, ok := formatExtensions[opts.format]; ok {
+		return ext
+	}
+	return ".yaml"
+}
+
+// normalizeExtension reads an --extension value such as json or .json
+func normalizeExtension(ext string) (string, error) {
+	if ext == "" {
+		return "", nil
+	}
+	ext = "." + strings.TrimPrefix(ext, ".")
+	for _, r := range ext[1:] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return "", fmt.Errorf("expected letters, digits, dots, dashes or underscores, got %q", ext)
+		}
+	}
+	if ext == "." {
+		return "", fmt.Errorf("the extension is empty")
+	}
+	return ext, nil
+}
+
+// valuesFilename builds the name of a values file from its parts joined by
+// dashes and the extension for the output format, e.g.
+// redis-19.0.1-default-values.yaml
+func valuesFilename(opts options, parts ...string) string {
+	return strings.Join(parts, "-") + valuesExtension(opts)
+}
+
+// withValuesExtension gives a name that may already carry an extension, such
+// as a bundled values file or a --filename-template result, the extension
+// for the output format. Names ending in .yaml or .yml keep it while YAML is
+// written without --extension, so values.yml is not renamed.
+func withValuesExtension(name string, opts options) string {
+	ext := path.Ext(name)
+	if opts.extension == "" && valuesExtension(opts) == ".yaml" && (ext == ".yaml" || ext == ".yml") {
+		return name
+	}
+	switch ext {
+	case ".yaml", ".yml", ".json", ".txt":
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name + valuesExtension(opts)
+}
+
+// valuesPath returns where the values of a chart version are written,
+// applying --filename-template, --output-dir and --nest-by-repo. A template's
+// name gets the extension of the output like every other values file.
+func valuesPath(repo HelmRepo, chart HelmVersion, opts options) (string, error) {
+	text := opts.filenameTemplate
+	if text == "" {
+		text = defaultFilenameTemplate
+	}
+	if text == defaultFilenameTemplate && opts.show != showAll {
+		data := templateData(repo, chart)
+		return filepath.Join(downloadDir(repo, opts), valuesFilename(opts, data.Chart, data.Version, "default-values")), nil
+	}
+	if text == defaultFilenameTemplate {
+		text = showAllFilenameTemplate
+	}
+	tmpl, err := parseFilenameTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+	if err := tmpl.Execute(&name, templateData(repo, chart)); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(name.String()) == "" {
+		return "", fmt.Errorf("filename template %q produced an empty name", text)
+	}
+	if opts.show == showAll {
+		return filepath.Join(downloadDir(repo, opts), name.String()), nil
+	}
+	return filepath.Join(downloadDir(repo, opts), withValuesExtension(name.String(), opts)), nil
+}
+
+// downloadDir returns the directory files for a repository are written to:
+// --output-dir, with a subdirectory per repository when --nest-by-repo is set
+func downloadDir(repo HelmRepo, opts options) string {
+	if !opts.nestByRepo || repo.Name == "" {
+		return opts.outputDir
+	}
+	return filepath.Join(opts.outputDir, repo.Name)
+}
+
+// writeValuesFile writes data to path, creating missing parent directories.
+// With --no-clobber an existing file is an error unless --force is also set.
+func writeValuesFile(path string, data []byte, opts options) error {
+	return writeValuesFileProgress(path, data, opts, nil)
+}
+
+// writeValuesFileProgress is writeValuesFile reporting the progress of
+// large writes
+func writeValuesFileProgress(path string, data []byte, opts options, report progressFunc) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// Creating the file exclusively leaves no gap between finding it missing
+	// and writing it
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if opts.noClobber && !opts.force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists, pass --force to overwrite it", path)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeWithProgress(file, data, "Writing", report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pinnedVersion returns the version pinned as a chart's default, if any
+func (c config) pinnedVersion(chart string) (string, bool) {
+	version, ok := c.Pins[chart]
+	return version, ok && version != ""
+}
+
+// isPinned reports whether a version is the one pinned for its chart
+func (m model) isPinned(version HelmVersion) bool {
+	pinned, ok := m.cfg.pinnedVersion(version.Name)
+	return ok && pinned == version.Version
+}
+
+// togglePin pins the highlighted version as its chart's default, or unpins
+// it when it already is, and saves the pins to the configuration file
+func (m model) togglePin() (model, tea.Cmd) {
+	if m.cursor >= len(m.versions) {
+		return m, nil
+	}
+	version := m.versions[m.cursor]
+
+	// Copy the pins so earlier model values keep their own
+	pins := make(map[string]string, len(m.cfg.Pins)+1)
+	for chart, pinned := range m.cfg.Pins {
+		pins[chart] = pinned
+	}
+	if m.isPinned(version) {
+		delete(pins, version.Name)
+	} else {
+		pins[version.Name] = version.Version
+	}
+	if len(pins) == 0 {
+		pins = nil
+	}
+	m.cfg.Pins = pins
+	return m, saveConfigCmd(m.opts.configPath, m.cfg, "pinned versions")
+}
+
+// cursorToPin highlights the chart's pinned version in a freshly loaded
+// version list, so Enter downloads it straight away
+func (m model) cursorToPin() model {
+	if len(m.versions) == 0 {
+		return m
+	}
+	pinned, ok := m.cfg.pinnedVersion(m.versions[0].Name)
+	if !ok {
+		return m
+	}
+	for i, version := range m.versions {
+		if version.Version == pinned {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helmPlugin describes a helm plugin an action depends on
+type helmPlugin struct {
+	name    string
+	repo    string
+	install string
+}
+
+// requiredPlugins maps actions to the plugin they need
+var requiredPlugins = map[versionAction]helmPlugin{
+	actionDiff: {
+		name:    "diff",
+		repo:    "helm-diff",
+		install: "helm plugin install https://github.com/databus23/helm-diff",
+	},
+}
+
+// pluginsLoadedMsg lists the installed helm plugins by name
+type pluginsLoadedMsg map[string]bool
+
+// diffLoadedMsg carries the output of helm diff for display
+type diffLoadedMsg struct {
+	release string
+	version HelmVersion
+	output  []byte
+}
+
+// loadPlugins checks which helm plugins are installed. A failing check is
+// treated as no plugins so the dependent actions stay hidden.
+func loadPlugins() tea.Cmd {
+	return func() tea.Msg {
+		output, err := runHelm(appContext, "plugin", "list")
+		if err != nil {
+			return pluginsLoadedMsg{}
+		}
+		return parsePluginList(output)
+	}
+}
+
+// parsePluginList reads the table printed by helm plugin list
+func parsePluginList(output []byte) pluginsLoadedMsg {
+	plugins := pluginsLoadedMsg{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "NAME" {
+			continue
+		}
+		plugins[fields[0]] = true
+	}
+	return plugins
+}
+
+// actionAvailable reports whether the plugin an action needs, if any, is installed
+func (m model) actionAvailable(action versionAction) bool {
+	plugin, ok := requiredPlugins[action]
+	return !ok || m.plugins[plugin.name]
+}
+
+// missingPlugin explains how to install the plugin an action needs
+func missingPlugin(action versionAction) string {
+	plugin := requiredPlugins[action]
+	return fmt.Sprintf("⚠️  This action needs the %s plugin. Install it with: %s", plugin.repo, plugin.install)
+}
+
+// diffRelease compares an installed release with a chart version using helm-diff
+func diffRelease(release string, version HelmVersion) tea.Cmd {
+	return func() tea.Msg {
+		output, err := runHelm(appContext, "diff", "upgrade", "--version", version.Version, "--", release, version.Name)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to diff release %s: %v", release, err))
+		}
+		if len(bytes.TrimSpace(output)) == 0 {
+			output = []byte("No changes.")
+		}
+		return diffLoadedMsg{release: release, version: version, output: output}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "sync"
+
+// runPool calls fn for every index in [0, n) using at most limit concurrent workers.
+// It is shared by all features that fan out helm commands so they honour --concurrency.
+func runPool(limit, n int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > n {
+		limit = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prefetchMsg reports one repository's chart list loaded by --prefetch
+type prefetchMsg struct {
+	repo   string
+	charts []HelmChart
+	ok     bool
+	ch     <-chan prefetchMsg
+}
+
+// prefetchDoneMsg is sent once every repository has been prefetched
+type prefetchDoneMsg struct{}
+
+// prefetchCharts loads the chart lists of all repositories in the background,
+// at most limit at a time, reporting each result as it arrives
+func prefetchCharts(repos []HelmRepo, limit int) tea.Cmd {
+	ch := make(chan prefetchMsg)
+
+	go func() {
+		runPool(limit, len(repos), func(i int) {
+			result := prefetchMsg{repo: repos[i].Name, ch: ch}
+			if charts, ok := loadCharts(repos[i].Name)().(chartsLoadedMsg); ok {
+				result.charts, result.ok = charts, true
+			}
+			ch <- result
+		})
+		close(ch)
+	}()
+
+	return waitForPrefetch(ch)
+}
+
+// waitForPrefetch waits for the next prefetch result
+func waitForPrefetch(ch <-chan prefetchMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return prefetchDoneMsg{}
+		}
+		return msg
+	}
+}
+
+// openRepo enters the chart list of a repository, using the session cache
+// when the charts have already been loaded. On a collapsed section's header
+// it expands the section instead.
+func (m model) openRepo(index int) (model, tea.Cmd) {
+	if m.repos[index].section != "" {
+		m.cursor = index
+		return m.toggleSection(), nil
+	}
+
+	m = m.push()
+	m.selectedRepo = index
+	m.cursor = 0
+	m.state = stateChartList
+	m.stats.viewRepo(m.repos[index].Name)
+
+	if charts, ok := m.chartCache[m.repos[index].Name]; ok {
+		m.charts = charts
+		m.loading = false
+		var sortCmd, typesCmd tea.Cmd
+		if m.chartSort == chartSortVersions || m.favoritesFirst {
+			m, sortCmd = m.sortCharts()
+		}
+		m, typesCmd = m.withChartTypes()
+		return m, tea.Batch(sortCmd, typesCmd)
+	}
+
+	m.loading = true
+	return m, loadCharts(m.repos[index].Name)
+}
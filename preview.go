@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Search match styles for the values preview
+var (
+	searchMatchStyle = lipgloss.NewStyle().
+				Reverse(true)
+
+	currentMatchStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("214")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+)
+
+// defaultViewportHeight is used until the terminal reports its size
+const defaultViewportHeight = 20
+
+// previewLoadedMsg carries the values shown in the preview
+type previewLoadedMsg []byte
+
+// viewport is a scrollable window over a block of text. When highlight is
+// set, lines are styled as they scroll into view and the result is cached.
+type viewport struct {
+	lines     []string
+	offset    int
+	height    int
+	title     string
+	highlight func(string) string
+	styled    map[int]string
+
+	// Search state: the query, the lines containing it and the current match
+	query   string
+	matches []int
+	match   int
+}
+
+// newViewport splits content into lines for scrolling
+func newViewport(content []byte, height int) viewport {
+	text := strings.TrimRight(string(content), "\n")
+	return viewport{lines: strings.Split(text, "\n"), height: height}
+}
+
+// scroll moves the window by delta lines, clamped to the content
+func (v *viewport) scroll(delta int) {
+	v.offset += delta
+	if maxOffset := len(v.lines) - v.height; v.offset > maxOffset {
+		v.offset = maxOffset
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+// view renders the visible lines
+func (v viewport) view() string {
+	end := v.offset + v.height
+	if end > len(v.lines) {
+		end = len(v.lines)
+	}
+
+	visible := v.lines[v.offset:end]
+	styled := make([]string, len(visible))
+	for i, line := range visible {
+		n := v.offset + i
+		switch {
+		case v.query != "" && containsFold(line, v.query):
+			styled[i] = markMatches(line, v.query, len(v.matches) > 0 && v.matches[v.match] == n)
+		case v.highlight != nil:
+			if _, ok := v.styled[n]; !ok {
+				v.styled[n] = v.highlight(line)
+			}
+			styled[i] = v.styled[n]
+		default:
+			styled[i] = line
+		}
+	}
+	return strings.Join(styled, "\n")
+}
+
+// search finds the lines containing query and scrolls to the first match.
+// An empty query clears the search.
+func (v *viewport) search(query string) {
+	v.query = query
+	v.matches = nil
+	v.match = 0
+	if query == "" {
+		return
+	}
+	for i, line := range v.lines {
+		if containsFold(line, query) {
+			v.matches = append(v.matches, i)
+		}
+	}
+	v.showMatch()
+}
+
+// nextMatch moves to the match delta positions away, wrapping around
+func (v *viewport) nextMatch(delta int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.match = (v.match + delta + len(v.matches)) % len(v.matches)
+	v.showMatch()
+}
+
+// showMatch scrolls so the current match is visible, a third of the way down
+func (v *viewport) showMatch() {
+	if len(v.matches) == 0 {
+		return
+	}
+	line := v.matches[v.match]
+	if line < v.offset || line >= v.offset+v.height {
+		v.offset = line - v.height/3
+		v.scroll(0)
+	}
+}
+
+// containsFold reports whether s contains substr, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// markMatches highlights every occurrence of query in line
+func markMatches(line, query string, current bool) string {
+	style := searchMatchStyle
+	if current {
+		style = currentMatchStyle
+	}
+
+	var s strings.Builder
+	lower, q := strings.ToLower(line), strings.ToLower(query)
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 || q == "" {
+			s.WriteString(line)
+			return s.String()
+		}
+		s.WriteString(line[:i])
+		s.WriteString(style.Render(line[i : i+len(q)]))
+		line, lower = line[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// loadPreview fetches the values of a chart version for display
+func loadPreview(chartName, version string) tea.Cmd {
+	return func() tea.Msg {
+		values, err := fetchValues(chartName, version)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to get chart values: %v", err))
+		}
+		return previewLoadedMsg(values)
+	}
+}
+
+// viewportHeight returns how many content lines fit on screen
+func (m model) viewportHeight() int {
+	if m.height == 0 {
+		return defaultViewportHeight
+	}
+	// Title, heading, scroll info and help take about ten lines
+	if h := m.height - 10; h > 3 {
+		return h
+	}
+	return 3
+}
+
+// updatePreview handles key presses while the values preview is shown
+func (m model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m.quit()
+	case "up", "k":
+		m.preview.scroll(-1)
+	case "down", "j":
+		m.preview.scroll(1)
+	case "pgup", "b":
+		m.preview.scroll(-m.preview.height)
+	case "pgdown", "f", " ":
+		// Space pages down here; in the lists it selects
+		m.preview.scroll(m.preview.height)
+	case "home":
+		m.preview.scroll(-len(m.preview.lines))
+	case "end":
+		m.preview.scroll(len(m.preview.lines))
+	case "/":
+		return m.openInput(inputPreviewSearch, "🔍 Search values:"), nil
+	case "n":
+		m.preview.nextMatch(1)
+	case "N":
+		m.preview.nextMatch(-1)
+	case "tab", "shift+tab":
+		if m.showingTabs {
+			delta := 1
+			if msg.String() == "shift+tab" {
+				delta = -1
+			}
+			m = m.switchTab(delta)
+		}
+	case "x":
+		if m.showingTabs {
+			m = m.closeTab()
+		}
+	case "esc", "backspace":
+		if m.preview.query != "" {
+			m.preview.search("")
+			return m, nil
+		}
+		m, _ = m.keepTab().back()
+	default:
+		// Other keys do nothing in the preview
+	}
+	return m, nil
+}
+
+// renderPreview draws the values preview
+func (m model) renderPreview() string {
+	var s strings.Builder
+	if m.showingTabs && len(m.tabs) > 0 {
+		s.WriteString(m.renderTabBar() + "\n\n")
+	}
+
+	title := m.preview.title
+	if title == "" {
+		version := m.versions[m.selectedVersion]
+		title = fmt.Sprintf("👀 Values of %s %s:", version.Name, version.Version)
+	}
+	s.WriteString(title + "\n\n")
+	s.WriteString(m.preview.view())
+	s.WriteString("\n\n")
+
+	last := m.preview.offset + m.preview.height
+	if last > len(m.preview.lines) {
+		last = len(m.preview.lines)
+	}
+	info := fmt.Sprintf("📄 Lines %d-%d of %d", m.preview.offset+1, last, len(m.preview.lines))
+	switch {
+	case m.preview.query != "" && len(m.preview.matches) == 0:
+		info += fmt.Sprintf(" • no matches for '%s'", m.preview.query)
+	case m.preview.query != "":
+		info += fmt.Sprintf(" • match %d of %d for '%s'", m.preview.match+1, len(m.preview.matches), m.preview.query)
+	}
+	s.WriteString(helpStyle.Render(info))
+	return s.String()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// progressThreshold is the size from which writing values reports progress
+	progressThreshold = 1 << 20
+	// progressChunk is how much is written between two progress reports
+	progressChunk = 256 << 10
+	// progressInterval limits how often progress is sent to the UI
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressFunc reports how many bytes of a step, such as writing or
+// hashing, are done
+type progressFunc func(step string, done, total int64)
+
+// progressMsg carries the progress of a background write. ch delivers the
+// following progress and, last, the write's result.
+type progressMsg struct {
+	step  string
+	done  int64
+	total int64
+	ch    <-chan tea.Msg
+}
+
+// progressWriter passes writes on to w, reporting the bytes written so far
+type progressWriter struct {
+	w      io.Writer
+	step   string
+	done   int64
+	total  int64
+	report progressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.report(p.step, p.done, p.total)
+	return n, err
+}
+
+// writeWithProgress writes data to w in chunks, reporting the progress of
+// step. Small data and a nil report write it in one go.
+func writeWithProgress(w io.Writer, data []byte, step string, report progressFunc) error {
+	if report == nil || len(data) < progressThreshold {
+		_, err := w.Write(data)
+		return err
+	}
+	pw := &progressWriter{w: w, step: step, total: int64(len(data)), report: report}
+	for start := 0; start < len(data); start += progressChunk {
+		if _, err := pw.Write(data[start:min(start+progressChunk, len(data))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withProgress runs work in the background, sending its progress as
+// progressMsgs and then its result
+func withProgress(work func(report progressFunc) tea.Msg) tea.Cmd {
+	ch := make(chan tea.Msg)
+	go func() {
+		defer close(ch)
+		var last time.Time
+		report := func(step string, done, total int64) {
+			if done < total && time.Since(last) < progressInterval {
+				return
+			}
+			last = time.Now()
+			select {
+			case ch <- progressMsg{step: step, done: done, total: total, ch: ch}:
+			case <-appContext.Done():
+			}
+		}
+		select {
+		case ch <- work(report):
+		case <-appContext.Done():
+		}
+	}()
+	return waitForProgress(ch)
+}
+
+// waitForProgress waits for the next progress report or the result
+func waitForProgress(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// formatSize renders a byte count such as 12.5 MiB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// updateProgress shows the progress of a background write and waits for
+// more
+func (m model) updateProgress(msg progressMsg) (tea.Model, tea.Cmd) {
+	percent := 100 * msg.done / max(msg.total, 1)
+	m.activity = fmt.Sprintf("💾 %s %s of %s (%d%%)...", msg.step, formatSize(msg.done), formatSize(msg.total), percent)
+	return m, waitForProgress(msg.ch)
+}
@@ -0,0 +1,33 @@
+package main
+
+// prompt is a confirmation the TUI can ask for before going ahead
+type prompt int
+
+const (
+	// promptArchive asks before archiving the values of every version
+	promptArchive prompt = iota
+	// promptQuit asks before quitting with selected versions not downloaded
+	promptQuit
+	// promptQuitDownload asks before quitting while a download is running
+	promptQuitDownload
+)
+
+// destructive reports whether assuming yes to the prompt loses work
+func (p prompt) destructive() bool {
+	return p == promptQuit || p == promptQuitDownload
+}
+
+// shouldConfirm reports whether to ask before going ahead. --no-prompts
+// assumes yes where that is safe; destructive prompts are only skipped when
+// --force is given as well.
+func (o options) shouldConfirm(p prompt) bool {
+	switch {
+	case p == promptQuit && o.noConfirmQuit:
+		return false
+	case !o.noPrompts:
+		return true
+	case p.destructive():
+		return !o.force
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// provenance records where a downloaded values file came from
+type provenance struct {
+	Repo         string    `json:"repo"`
+	RepoURL      string    `json:"repo_url"`
+	Chart        string    `json:"chart"`
+	Version      string    `json:"version"`
+	AppVersion   string    `json:"app_version"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	HelmVersion  string    `json:"helm_version"`
+	ValuesSHA256 string    `json:"values_sha256"`
+}
+
+// provenancePath returns the sidecar path for a values file
+func provenancePath(valuesPath string) string {
+	return strings.TrimSuffix(valuesPath, filepath.Ext(valuesPath)) + ".provenance.json"
+}
+
+// helmVersion returns the short version string of the installed helm binary
+func helmVersion() string {
+	output, err := runHelm(appContext, "version", "--short")
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// writeProvenance writes the provenance sidecar next to the values file,
+// reporting the progress of hashing large values. The sidecar follows the
+// same overwrite rules as the values file.
+func writeProvenance(valuesPath string, repo HelmRepo, version HelmVersion, values []byte, opts options, report progressFunc) error {
+	hash := sha256.New()
+	if err := writeWithProgress(hash, values, "Hashing", report); err != nil {
+		return err
+	}
+
+	record := provenance{
+		Repo:         repo.Name,
+		RepoURL:      repo.URL,
+		Chart:        version.Name,
+		Version:      version.Version,
+		AppVersion:   version.AppVersion,
+		DownloadedAt: time.Now().UTC(),
+		HelmVersion:  helmVersion(),
+		ValuesSHA256: hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeValuesFile(provenancePath(valuesPath), append(data, '\n'), opts)
+}
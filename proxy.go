@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// parseProxy reads a --proxy URL such as http://proxy.example.com:3128
+func parseProxy(value string) (*url.URL, error) {
+	proxy, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected a URL such as http://proxy:3128: %w", err)
+	}
+	if proxy.Scheme != "http" && proxy.Scheme != "https" && proxy.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported scheme %q, use http, https or socks5", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", value)
+	}
+	return proxy, nil
+}
+
+// newTransport returns the transport for helm-browser's own HTTP calls. They
+// go through proxy when it is set and otherwise follow HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY, as helm does.
+func newTransport(proxy *url.URL) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport
+}
+
+// useProxy sends the ArtifactHub, OCI registry and reachability check
+// requests through proxy
+func useProxy(proxy *url.URL) {
+	ociClient.Transport = newTransport(proxy)
+	artifactHubClient.Transport = newTransport(proxy)
+	repoCheckClient.Transport = newTransport(proxy)
+}
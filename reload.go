@@ -0,0 +1,91 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultReloadKey reloads the current list when the config file sets no reload_key
+const defaultReloadKey = "ctrl+r"
+
+// reloadTarget remembers what was highlighted when a list was reloaded, so
+// the cursor can return to it once the list is loaded again
+type reloadTarget struct {
+	active bool
+	item   string
+	cursor int
+}
+
+// reloadKey returns the key that reloads the current list
+func (m model) reloadKey() string {
+	if m.cfg.ReloadKey != "" {
+		return m.cfg.ReloadKey
+	}
+	return defaultReloadKey
+}
+
+// canReload reports whether the current screen is a list that can be reloaded
+func (m model) canReload() bool {
+	switch m.state {
+	case stateRepoList, stateChartList, stateVersionList:
+		return !m.loading
+	}
+	return false
+}
+
+// highlighted returns the name of the item under the cursor: the repository,
+// chart or version
+func (m model) highlighted() string {
+	switch {
+	case m.state == stateRepoList && m.cursor < len(m.repos):
+		return m.repos[m.cursor].Name
+	case m.state == stateChartList && m.cursor < len(m.charts):
+		return m.charts[m.cursor].Name
+	case m.state == stateVersionList && m.cursor < len(m.versions):
+		return m.versions[m.cursor].Version
+	}
+	return ""
+}
+
+// reloadList runs the current list's load command again, without helm repo
+// update and bypassing the session's chart cache, so changes to the local
+// repository files show up
+func (m model) reloadList() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.state {
+	case stateRepoList:
+		cmd = loadRepos()
+	case stateChartList:
+		if m.searchQuery != "" {
+			cmd = searchAllCharts(m.searchQuery, m.opts.exact)
+		} else {
+			cmd = loadCharts(m.repos[m.selectedRepo].Name)
+		}
+	case stateVersionList:
+		cmd = loadVersions(m.charts[m.selectedChart].Name, m.devel)
+	default:
+		return m, nil
+	}
+
+	m.reload = reloadTarget{active: true, item: m.highlighted(), cursor: m.cursor}
+	m.loading = true
+	return m, cmd
+}
+
+// restoreCursor moves the cursor back to the item highlighted before a
+// reload or, when it is gone, to the same position in the list
+func (m model) restoreCursor() model {
+	if !m.reload.active {
+		return m
+	}
+	target := m.reload
+	m.reload = reloadTarget{}
+
+	for i := 0; i < m.getItemCount(); i++ {
+		m.cursor = i
+		if m.highlighted() == target.item {
+			return m
+		}
+	}
+	m.cursor = min(target.cursor, max(m.getItemCount()-1, 0))
+	return m
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// otherGroup is the section for repositories without a configured group
+const otherGroup = "Other"
+
+// Repository sort orders
+const (
+	sortHelm = "helm"
+	sortName = "name"
+	sortURL  = "url"
+)
+
+// repoSorts lists the sort orders in the order the s key cycles through them
+var repoSorts = []string{sortHelm, sortName, sortURL}
+
+// applyRepoView rebuilds the displayed repository list from allRepos,
+// keeping the cursor on the same repository where possible. A collapsed
+// section is a single row, its header, so the cursor can expand it again.
+func (m model) applyRepoView() model {
+	var current HelmRepo
+	if m.cursor < len(m.repos) {
+		current = m.repos[m.cursor]
+	}
+
+	repos := make([]HelmRepo, 0, len(m.allRepos))
+	for _, repo := range m.allRepos {
+		if m.groupRepos && m.collapsed[m.repoGroup(repo.Name)] || m.hideUnreachable && m.unreachable[repo.Name] {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	if m.groupRepos {
+		for _, group := range m.groupOrder() {
+			if m.collapsed[group] {
+				repos = append(repos, HelmRepo{section: group})
+			}
+		}
+	}
+
+	switch m.repoSort {
+	case sortName:
+		sort.SliceStable(repos, func(i, j int) bool {
+			return strings.ToLower(repos[i].Name) < strings.ToLower(repos[j].Name)
+		})
+	case sortURL:
+		sort.SliceStable(repos, func(i, j int) bool {
+			hi, hj := urlHost(repos[i].URL), urlHost(repos[j].URL)
+			if hi != hj {
+				return hi < hj
+			}
+			return strings.ToLower(repos[i].Name) < strings.ToLower(repos[j].Name)
+		})
+	}
+
+	// Favorites first, within their section when grouping
+	if m.favoritesFirst {
+		sort.SliceStable(repos, func(i, j int) bool {
+			return m.isFavorite(repos[i].Name) && !m.isFavorite(repos[j].Name)
+		})
+	}
+
+	if m.groupRepos {
+		order := m.groupOrder()
+		rank := make(map[string]int, len(order))
+		for i, g := range order {
+			rank[g] = i
+		}
+		sort.SliceStable(repos, func(i, j int) bool {
+			return rank[m.rowGroup(repos[i])] < rank[m.rowGroup(repos[j])]
+		})
+	}
+
+	m.repos = repos
+	m.cursor = 0
+	for i, repo := range repos {
+		if repo.Name == current.Name && repo.section == current.section {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// nextRepoSort switches to the following sort order and returns to the top
+// of the list
+func (m model) nextRepoSort() model {
+	for i, order := range repoSorts {
+		if order == m.repoSort {
+			m.repoSort = repoSorts[(i+1)%len(repoSorts)]
+			break
+		}
+	}
+	m = m.applyRepoView()
+	m.cursor = 0
+	return m
+}
+
+// orderRepos applies the saved repository order: listed repositories come
+// first in that order, the rest follow in the order helm reports them
+func orderRepos(repos []HelmRepo, order []string) []HelmRepo {
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		rank[name] = i
+	}
+
+	ordered := append([]HelmRepo(nil), repos...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, iok := rank[ordered[i].Name]
+		rj, jok := rank[ordered[j].Name]
+		if iok != jok {
+			return iok
+		}
+		return iok && ri < rj
+	})
+	return ordered
+}
+
+// configSavedMsg reports the outcome of saving the configuration file
+type configSavedMsg struct {
+	what string
+	err  error
+}
+
+// saveConfigCmd writes the configuration file in the background
+func saveConfigCmd(path string, cfg config, what string) tea.Cmd {
+	return func() tea.Msg {
+		return configSavedMsg{what: what, err: saveConfig(path, cfg)}
+	}
+}
+
+// moveRepo swaps the highlighted repository with the one above (delta -1)
+// or below (delta 1) and saves the new order to the configuration file
+func (m model) moveRepo(delta int) (model, tea.Cmd) {
+	target := m.cursor + delta
+	if m.loading || target < 0 || target >= len(m.repos) {
+		return m, nil
+	}
+	if m.repoSort != sortHelm {
+		m.status = errorStyle.Render("⚠️  Repositories can only be moved in the helm order (press s)")
+		return m, nil
+	}
+
+	moving, other := m.repos[m.cursor].Name, m.repos[target].Name
+	if m.groupRepos && m.rowGroup(m.repos[m.cursor]) != m.rowGroup(m.repos[target]) {
+		m.status = errorStyle.Render("⚠️  Repositories can only be moved within their section")
+		return m, nil
+	}
+
+	// Copy before swapping so earlier model values keep their order
+	repos := append([]HelmRepo(nil), m.allRepos...)
+	from, to := repoIndex(repos, moving), repoIndex(repos, other)
+	repos[from], repos[to] = repos[to], repos[from]
+
+	order := make([]string, len(repos))
+	for i, repo := range repos {
+		order[i] = repo.Name
+	}
+	m.allRepos = repos
+	m.cfg.Order = order
+	m.rows = make(rowCache)
+	m = m.applyRepoView()
+	return m, saveConfigCmd(m.opts.configPath, m.cfg, "repository order")
+}
+
+// repoIndex returns the position of a repository in the list, or -1. A
+// trailing slash is ignored and, without an exact match, so is casing.
+func repoIndex(repos []HelmRepo, name string) int {
+	name = normalizeRepoName(name)
+	match := -1
+	for i, repo := range repos {
+		switch {
+		case repo.Name == name:
+			return i
+		case match < 0 && strings.EqualFold(repo.Name, name):
+			match = i
+		}
+	}
+	return match
+}
+
+// normalizeRepoName trims the spaces and trailing slashes a repository name
+// may be typed with
+func normalizeRepoName(name string) string {
+	return strings.TrimRight(strings.TrimSpace(name), "/")
+}
+
+// urlHost returns the lower-cased host of a repository URL, or the URL
+// itself when it cannot be parsed (e.g. oci:// references without a host)
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// repoGroup returns the configured section for a repository
+func (m model) repoGroup(name string) string {
+	if group := strings.TrimSpace(m.cfg.Groups[name]); group != "" {
+		return group
+	}
+	return otherGroup
+}
+
+// groupOrder lists the sections that contain at least one repository,
+// alphabetically with "Other" last
+func (m model) groupOrder() []string {
+	seen := map[string]bool{}
+	var groups []string
+	for _, repo := range m.allRepos {
+		group := m.repoGroup(repo.Name)
+		if !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i] == otherGroup) != (groups[j] == otherGroup) {
+			return groups[j] == otherGroup
+		}
+		return groups[i] < groups[j]
+	})
+	return groups
+}
+
+// rowGroup returns the section a row of the repository list belongs to
+func (m model) rowGroup(repo HelmRepo) string {
+	if repo.section != "" {
+		return repo.section
+	}
+	return m.repoGroup(repo.Name)
+}
+
+// repoCount returns how many repositories the list shows, leaving out the
+// headers of collapsed sections
+func (m model) repoCount() int {
+	count := 0
+	for _, repo := range m.repos {
+		if repo.section == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// toggleSection collapses the section of the highlighted row, or expands it
+// when the row is a collapsed section's header. The cursor stays on the
+// section: on its header when collapsed, on its first repository when expanded.
+func (m model) toggleSection() model {
+	if m.cursor >= len(m.repos) {
+		return m
+	}
+	group := m.rowGroup(m.repos[m.cursor])
+
+	// Copy the sections so earlier model values keep their own
+	collapsed := make(map[string]bool, len(m.collapsed)+1)
+	for g := range m.collapsed {
+		collapsed[g] = true
+	}
+	if collapsed[group] {
+		delete(collapsed, group)
+	} else {
+		collapsed[group] = true
+	}
+	m.collapsed = collapsed
+
+	m = m.applyRepoView()
+	for i, repo := range m.repos {
+		if m.rowGroup(repo) == group {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// groupHeader renders a section header row for the repository list
+func (m model) groupHeader(group string) string {
+	count := 0
+	for _, repo := range m.allRepos {
+		if m.repoGroup(repo.Name) == group {
+			count++
+		}
+	}
+
+	marker := "▾"
+	if m.collapsed[group] {
+		marker = "▸"
+	}
+	return groupHeaderStyle.Render(fmt.Sprintf("%s %s (%d)", marker, group, count))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultRetries is how many times a failed network command is retried
+// when --retries is not given
+const defaultRetries = 2
+
+// commandRetries is how many times a failed network command is retried;
+// main sets it from --retries
+var commandRetries = 0
+
+// retryDelay is the wait before the first retry; each later retry waits
+// twice as long as the one before
+var retryDelay = time.Second
+
+// isNetworkCommand reports whether a helm command reaches repositories or
+// registries, so that a failure may be a transient network hiccup
+func isNetworkCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "show", "pull", "registry":
+		return true
+	case "repo":
+		return len(args) > 1 && (args[1] == "update" || args[1] == "add")
+	case "search":
+		return len(args) > 1 && args[1] == "hub"
+	}
+	return false
+}
+
+// isPermanent reports whether retrying a failed command is pointless: it
+// was cancelled, or helm says the chart or version does not exist
+func isPermanent(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || appContext.Err() != nil {
+		return true
+	}
+	text := strings.ToLower(err.Error())
+	return strings.Contains(text, "not found") || strings.Contains(text, "no repo named")
+}
+
+// withRetries runs a network command, retrying failures up to
+// commandRetries times with exponential backoff. Each failed attempt is
+// logged; only the last error is returned.
+func withRetries(ctx context.Context, args []string, run func() ([]byte, error)) ([]byte, error) {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		output, err := run()
+		if err == nil || attempt > commandRetries || isPermanent(ctx, err) {
+			return output, err
+		}
+
+		sessionLog.log(logEntry{
+			Event:   "retry",
+			Command: append([]string{"helm"}, args...),
+			Error:   fmt.Sprintf("attempt %d of %d failed, retrying in %s: %v", attempt, commandRetries+1, delay, err),
+		})
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "strings"
+
+// rowKey identifies a rendered list row
+type rowKey struct {
+	state    state
+	index    int
+	selected bool
+	width    int
+}
+
+// rowEntry is a rendered row together with the data it was rendered from
+type rowEntry struct {
+	data string
+	line string
+}
+
+// rowCache keeps rendered list rows so that moving the cursor only restyles
+// the rows whose selection changed. An entry is reused only while the row's
+// data is unchanged; loading a list or resizing the terminal clears it.
+type rowCache map[rowKey]rowEntry
+
+// row returns the cached rendering for key, calling render when the row is
+// missing or its data has changed
+func (c rowCache) row(key rowKey, data string, render func() string) string {
+	if entry, ok := c[key]; ok && entry.data == data {
+		return entry.line
+	}
+	line := render()
+	c[key] = rowEntry{data: data, line: line}
+	return line
+}
+
+// rowData joins the values a row is rendered from into a cache signature
+func rowData(values ...string) string {
+	return strings.Join(values, "\x00")
+}
@@ -0,0 +1,63 @@
+package main
+
+import "fmt"
+
+// listChrome is roughly how many lines the title, headers, info and help
+// lines take around a --no-paging list
+const listChrome = 14
+
+// listHeight returns how many rows a --no-paging list shows at once
+func (m model) listHeight() int {
+	if m.height == 0 {
+		return pageSize
+	}
+	if h := m.height - listChrome; h > 3 {
+		return h
+	}
+	return 3
+}
+
+// scrollList keeps the cursor within the rows shown by a --no-paging list,
+// moving the window as little as possible
+func (m model) scrollList() model {
+	if !m.opts.noPaging {
+		return m
+	}
+
+	height := m.pageLen()
+	if m.cursor < m.listOffset {
+		m.listOffset = m.cursor
+	}
+	if m.cursor >= m.listOffset+height {
+		m.listOffset = m.cursor - height + 1
+	}
+	if last := m.getItemCount() - height; m.listOffset > last {
+		m.listOffset = last
+	}
+	if m.listOffset < 0 {
+		m.listOffset = 0
+	}
+	return m
+}
+
+// rowLabel returns the number shown before the item at index i. With
+// --no-paging and in the repository grid only the first ten rows in view
+// are numbered, matching the number keys.
+func (m model) rowLabel(i int) string {
+	if !m.opts.noPaging && m.repoColumns() == 1 {
+		return pageNumber(i)
+	}
+	if position := i - m.getPageStart(); position < pageSize {
+		return fmt.Sprintf("%d.", position+1)
+	}
+	return ""
+}
+
+// scrollInfo describes which rows of a --no-paging list are in view, or
+// returns "" when the whole list fits or the list is paged
+func (m model) scrollInfo(total int, noun string) string {
+	if !m.opts.noPaging || total <= m.pageLen() {
+		return ""
+	}
+	return fmt.Sprintf("📄 Rows %d-%d of %d %s", m.getPageStart()+1, m.getPageEnd(total), total, noun)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchCharts searches all configured repositories for charts matching term
+func searchCharts(term string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := runHelm(appContext, "search", "repo", "-o", "json", "--", term)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to search charts: %v", err))
+		}
+
+		var charts []HelmChart
+		if len(output) > 0 {
+			if err := json.Unmarshal(output, &charts); err != nil {
+				return errorMsg(fmt.Sprintf("Failed to parse charts: %v", err))
+			}
+		}
+
+		return chartsLoadedMsg(dedupeCharts(charts))
+	}
+}
+
+// searchResultsMsg carries charts found across all repositories
+type searchResultsMsg []HelmChart
+
+// searchAllCharts runs a search across all repositories for the TUI. With
+// exact only the charts named term are kept.
+func searchAllCharts(term string, exact bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := searchCharts(term)()
+		if charts, ok := msg.(chartsLoadedMsg); ok {
+			if exact {
+				charts = exactCharts(charts, term)
+			}
+			return searchResultsMsg(charts)
+		}
+		return msg
+	}
+}
+
+// exactCharts keeps the charts whose name without the repository equals
+// term, or whose full name does when term names the repository too. helm
+// search matches substrings, so "redis" also finds redis-cluster.
+func exactCharts(charts []HelmChart, term string) []HelmChart {
+	var exact []HelmChart
+	for _, chart := range charts {
+		if chart.Name == term || path.Base(chart.Name) == term {
+			exact = append(exact, chart)
+		}
+	}
+	return exact
+}
+
+// dedupeCharts drops repeated entries for the same fully-qualified chart name,
+// which helm returns when a repository is configured more than once
+func dedupeCharts(charts []HelmChart) []HelmChart {
+	seen := make(map[string]bool, len(charts))
+	unique := charts[:0:0]
+	for _, chart := range charts {
+		if seen[chart.Name] {
+			continue
+		}
+		seen[chart.Name] = true
+		unique = append(unique, chart)
+	}
+	return unique
+}
+
+// repoFor returns the repository a fully-qualified chart name belongs to
+func (m model) repoFor(chartName string) HelmRepo {
+	return repoForChart(m.allRepos, chartName)
+}
+
+// repoForChart returns the repository a fully-qualified chart name belongs
+// to. The longest matching repository name wins, so the result is right even
+// when one repository name is a prefix of another.
+func repoForChart(repos []HelmRepo, chartName string) HelmRepo {
+	if isOCIRef(chartName) {
+		return ociRepo(chartName)
+	}
+	var best HelmRepo
+	for _, repo := range repos {
+		if strings.HasPrefix(chartName, repo.Name+"/") && len(repo.Name) > len(best.Name) {
+			best = repo
+		}
+	}
+	if best.Name != "" {
+		return best
+	}
+
+	name, _, _ := strings.Cut(chartName, "/")
+	return HelmRepo{Name: name}
+}
+
+// filterRepoCharts keeps the charts of one repository. helm search matches
+// keywords as substrings, so searching "bitnami/" also returns the charts of
+// a repository named "my-bitnami". It also ignores casing, so a repository
+// name typed as Bitnami still finds the charts of bitnami, unless another
+// repository is named exactly that.
+func filterRepoCharts(charts []HelmChart, repoName string) []HelmChart {
+	repoName = normalizeRepoName(repoName)
+	var exact, folded []HelmChart
+	for _, chart := range charts {
+		prefix, _, ok := strings.Cut(chart.Name, "/")
+		switch {
+		case !ok:
+		case prefix == repoName:
+			exact = append(exact, chart)
+		case strings.EqualFold(prefix, repoName):
+			folded = append(folded, chart)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return folded
+}
+
+// chartLabel returns how a chart is named in lists and titles: without the
+// repository prefix when browsing a single repository
+func (m model) chartLabel(chartName string) string {
+	if m.searchQuery != "" || m.selectedRepo >= len(m.repos) {
+		return chartName
+	}
+	return strings.TrimPrefix(chartName, m.repos[m.selectedRepo].Name+"/")
+}
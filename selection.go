@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchDownloadMsg reports the outcome of downloading every selected version
+type batchDownloadMsg struct {
+	paths  []string
+	keys   []string
+	failed []string
+}
+
+// toggleSelected adds or removes the highlighted version from the selection
+func (m model) toggleSelected() model {
+	if m.cursor >= len(m.versions) {
+		return m
+	}
+
+	version := m.versions[m.cursor]
+	key := downloadKey(version.Name, version.Version)
+	if _, ok := m.selected[key]; ok {
+		delete(m.selected, key)
+	} else {
+		m.selected[key] = version
+	}
+	return m
+}
+
+// isSelected reports whether a version is part of the selection
+func (m model) isSelected(version HelmVersion) bool {
+	_, ok := m.selected[downloadKey(version.Name, version.Version)]
+	return ok
+}
+
+// selectedKeys returns the keys of the selected versions in a stable order
+func (m model) selectedKeys() []string {
+	keys := make([]string, 0, len(m.selected))
+	for key := range m.selected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// downloadSelected downloads the values of every selected version, at most
+// opts.concurrency at a time, and reports them in the order of their keys
+func (m model) downloadSelected() (tea.Model, tea.Cmd) {
+	if len(m.selected) == 0 {
+		return m, nil
+	}
+
+	keys := m.selectedKeys()
+	versions := make([]HelmVersion, len(keys))
+	repos := make([]HelmRepo, len(keys))
+	for i, key := range keys {
+		versions[i] = m.selected[key]
+		repos[i] = m.repoFor(versions[i].Name)
+	}
+
+	m = m.push()
+	m.loading = true
+	m.activity = fmt.Sprintf("⬇️  Downloading %d values files...", len(keys))
+	m.state = stateDownload
+
+	opts := m.opts
+	return m, func() tea.Msg {
+		msgs := make([]tea.Msg, len(versions))
+		runPool(opts.concurrency, len(versions), func(i int) {
+			msgs[i] = downloadValues(repos[i], versions[i], opts)()
+		})
+
+		var result batchDownloadMsg
+		for i, version := range versions {
+			switch msg := msgs[i].(type) {
+			case downloadCompleteMsg:
+				result.paths = append(result.paths, msg.path)
+				result.keys = append(result.keys, keys[i])
+			case errorMsg:
+				result.failed = append(result.failed, fmt.Sprintf("%s %s: %s", version.Name, version.Version, msg))
+			}
+		}
+		return result
+	}
+}
+
+// quit exits the application, asking first when selected versions have
+// not been downloaded yet or a download is still running
+func (m model) quit() (tea.Model, tea.Cmd) {
+	pending := len(m.selected) > 0 && m.opts.shouldConfirm(promptQuit)
+	if pending || m.downloading() && m.opts.shouldConfirm(promptQuitDownload) {
+		m.confirmQuit = true
+		m.menuOpen = false
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// downloading reports whether a download, or another command started for a
+// version, is still running and would be stopped by quitting
+func (m model) downloading() bool {
+	return m.loading && m.state == stateDownload
+}
+
+// updateConfirmQuit handles the answer to the quit confirmation
+func (m model) updateConfirmQuit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmQuit = false
+	switch msg.String() {
+	case "y", "Y", "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderConfirmQuit draws the quit confirmation with what is still running
+// and the pending selection
+func (m model) renderConfirmQuit() string {
+	var s strings.Builder
+	if m.downloading() {
+		s.WriteString(errorStyle.Render("⚠️  Quitting stops what is still running: "+strings.TrimSpace(m.activity)) + "\n")
+	}
+	if len(m.selected) > 0 {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("⚠️  %d selected versions have not been downloaded:", len(m.selected))) + "\n")
+		for _, key := range m.selectedKeys() {
+			version := m.selected[key]
+			s.WriteString(fmt.Sprintf("   • %s %s\n", version.Name, version.Version))
+		}
+	}
+	s.WriteString("\n")
+	s.WriteString(selectedStyle.Render("Quit anyway? (y/N)"))
+	return s.String()
+}
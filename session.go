@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sessionScriptName is the file the browse session is exported to
+const sessionScriptName = "helm-browser-session.sh"
+
+// stepKind is the kind of action recorded in the session history
+type stepKind int
+
+// Session step kinds
+const (
+	stepValues stepKind = iota
+	stepPull
+	stepSubchart
+	stepValuesFile
+)
+
+// sessionStep is one completed action, recorded for the session export
+type sessionStep struct {
+	kind    stepKind
+	repo    HelmRepo
+	version HelmVersion
+	path    string
+
+	// source is the subchart or bundled values file the values came from
+	source string
+}
+
+// record appends a completed action on a version to the session history
+func (m model) record(kind stepKind, version HelmVersion, path, source string) model {
+	m.history = append(m.history, sessionStep{
+		kind:    kind,
+		repo:    m.repoFor(version.Name),
+		version: version,
+		path:    path,
+		source:  source,
+	})
+	return m
+}
+
+// sessionScript renders the session history and pending selections as a
+// shell script of equivalent helm commands
+func (m model) sessionScript() string {
+	var s strings.Builder
+	s.WriteString("#!/bin/sh\n")
+	s.WriteString(fmt.Sprintf("# Exported by helm-browser on %s\n", time.Now().Format(time.RFC3339)))
+	s.WriteString("set -e\n")
+
+	// Repositories first, in the order they were first used
+	seen := map[string]bool{}
+	var repos []HelmRepo
+	for _, step := range m.history {
+		if !seen[step.repo.Name] {
+			seen[step.repo.Name] = true
+			repos = append(repos, step.repo)
+		}
+	}
+	for _, key := range m.selectedKeys() {
+		if repo := m.repoFor(m.selected[key].Name); !seen[repo.Name] {
+			seen[repo.Name] = true
+			repos = append(repos, repo)
+		}
+	}
+
+	if len(repos) > 0 {
+		s.WriteString("\n# Repositories\n")
+		for _, repo := range repos {
+			if repo.URL == "" {
+				s.WriteString(fmt.Sprintf("# %s: repository URL unknown, add it manually\n", repo.Name))
+				continue
+			}
+			s.WriteString(fmt.Sprintf("helm repo add %s %s --force-update\n", shellQuote(repo.Name), shellQuote(repo.URL)))
+		}
+		s.WriteString("helm repo update\n")
+	}
+
+	if len(m.history) > 0 {
+		s.WriteString("\n# Actions\n")
+		for _, step := range m.history {
+			chart, version := shellQuote(step.version.Name), shellQuote(step.version.Version)
+			switch step.kind {
+			case stepValues:
+				if m.opts.stripComments {
+					s.WriteString("# helm-browser re-emitted these values without comments (--strip-comments)\n")
+				}
+				if m.opts.minify {
+					s.WriteString("# helm-browser dropped null and empty keys from these values (--minify)\n")
+				}
+				s.WriteString(showValuesCommand(step.version, step.path))
+			case stepPull:
+				s.WriteString(fmt.Sprintf("helm pull %s --version %s\n", chart, version))
+			case stepSubchart:
+				s.WriteString(fmt.Sprintf("# Values of subchart %s were extracted from this chart into %s\n", step.source, step.path))
+				s.WriteString(fmt.Sprintf("helm pull %s --version %s --untar\n", chart, version))
+			case stepValuesFile:
+				s.WriteString(fmt.Sprintf("# The bundled %s was extracted from this chart into %s\n", step.source, step.path))
+				s.WriteString(fmt.Sprintf("helm pull %s --version %s --untar\n", chart, version))
+			}
+		}
+	}
+
+	if len(m.selected) > 0 {
+		s.WriteString("\n# Selected but not downloaded yet\n")
+		for _, key := range m.selectedKeys() {
+			version := m.selected[key]
+			path, err := valuesPath(m.repoFor(version.Name), version, m.opts)
+			if err != nil {
+				continue
+			}
+			s.WriteString(showValuesCommand(version, path))
+		}
+	}
+
+	return s.String()
+}
+
+// showValuesCommand returns the commands writing the values of a version to path
+func showValuesCommand(version HelmVersion, path string) string {
+	cmd := fmt.Sprintf("helm show values %s --version %s > %s\n", shellQuote(version.Name), shellQuote(version.Version), shellQuote(path))
+	if dir := filepath.Dir(path); dir != "." {
+		cmd = fmt.Sprintf("mkdir -p %s\n", shellQuote(dir)) + cmd
+	}
+	return cmd
+}
+
+// exportSession writes the session script to the current directory
+func (m model) exportSession() model {
+	if len(m.history) == 0 && len(m.selected) == 0 {
+		m.status = errorStyle.Render("⚠️  Nothing to export yet: download, pull or select a version first")
+		return m
+	}
+
+	if err := os.WriteFile(sessionScriptName, []byte(m.sessionScript()), 0755); err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("❌ Export failed: %v", err))
+		return m
+	}
+	m.status = downloadedStyle.Render(fmt.Sprintf("📜 Session exported to %s", sessionScriptName))
+	return m
+}
+
+// shellQuote quotes s for a POSIX shell when it contains special characters
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@+=,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
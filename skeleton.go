@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// skeletonWidths varies the placeholder bar lengths so the rows read like a list
+var skeletonWidths = []int{22, 16, 26, 12, 19, 24, 14, 20, 17, 25}
+
+// chartListHeader returns the column headers of the chart list, shared with
+// its loading skeleton so the layout does not move when the charts arrive
+func (m model) chartListHeader() string {
+	countHeader, countRule := "", ""
+	if m.chartSort == chartSortVersions {
+		countHeader, countRule = fmt.Sprintf("%-9s ", "VERSIONS"), fmt.Sprintf("%-9s ", "────────")
+	}
+
+	// Extra --columns go between the name and the version
+	columnHeader, columnRule := m.columnHeaders()
+
+	var s strings.Builder
+	if m.appVersionColumn {
+		s.WriteString(fmt.Sprintf("%-4s %-30s%s %s%s\n", "", "CHART NAME", columnHeader, countHeader, "APP VERSION"))
+		s.WriteString(fmt.Sprintf("%-4s %-30s%s %s%s\n", "────", "──────────────────────────────", columnRule, countRule, "───────────"))
+	} else {
+		s.WriteString(fmt.Sprintf("%-4s %-30s%s %s%s\n", "", "CHART NAME", columnHeader, countHeader, "VERSION"))
+		s.WriteString(fmt.Sprintf("%-4s %-30s%s %s%s\n", "────", "──────────────────────────────", columnRule, countRule, "───────"))
+	}
+	return s.String()
+}
+
+// chartSkeleton renders a page of greyed placeholder rows shown while the
+// chart list loads
+func (m model) chartSkeleton() string {
+	var s strings.Builder
+	s.WriteString(m.chartListHeader())
+
+	for i := 0; i < pageSize; i++ {
+		width := skeletonWidths[i%len(skeletonWidths)]
+		name := skeletonStyle.Render(strings.Repeat("█", width)) + strings.Repeat(" ", 30-width+(columnWidth+1)*len(m.opts.columns))
+		version := skeletonStyle.Render(strings.Repeat("█", 6+i%3))
+		if m.chartSort == chartSortVersions {
+			version = skeletonStyle.Render("██") + strings.Repeat(" ", 8) + version
+		}
+		s.WriteString(fmt.Sprintf("  %-4s %s %s\n", "", name, version))
+	}
+	return s.String()
+}
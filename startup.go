@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// startupSteps name the steps shown while helm-browser starts, in order
+var startupSteps = []string{"Updating repos", "Loading list"}
+
+// startupProgress tracks the startup steps. It moves on as the startup
+// messages arrive: repoUpdateMsg ends the update and reposLoadedMsg the
+// whole startup.
+type startupProgress struct {
+	step    int  // index in startupSteps of the running step
+	warning bool // the update failed and cached data is used
+}
+
+// renderStartup draws the startup steps on one line with a small bar, e.g.
+//
+//	▰▰▰▱▱▱  1/2 Updating repos ✓ · 2/2 Loading list…
+func (m model) renderStartup() string {
+	const segment = 3
+	bar := strings.Repeat("▰", m.startup.step*segment) + strings.Repeat("▱", (len(startupSteps)-m.startup.step)*segment)
+
+	parts := make([]string, len(startupSteps))
+	for i, name := range startupSteps {
+		label := fmt.Sprintf("%d/%d %s", i+1, len(startupSteps), name)
+		switch {
+		case i < m.startup.step && m.startup.warning && i == 0:
+			parts[i] = errorStyle.Render(label + " ⚠️")
+		case i < m.startup.step:
+			parts[i] = downloadedStyle.Render(label + " ✓")
+		case i == m.startup.step:
+			parts[i] = selectedStyle.Render(label + "…")
+		default:
+			parts[i] = appVersionStyle.Render(label)
+		}
+	}
+	return fmt.Sprintf("%s  %s\n", bar, strings.Join(parts, " · "))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinResult is the outcome of one chart reference read from stdin
+type stdinResult struct {
+	Ref string `json:"ref"`
+	downloadResult
+	Error string `json:"error,omitempty"`
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// parseChartRef splits a repo/chart[:version] reference. The version
+// separator is only looked for after the last slash.
+func parseChartRef(ref string) (chart, version string) {
+	slash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > slash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, ""
+}
+
+// runStdin downloads the values of every repo/chart[:version] line read
+// from stdin, using the latest version when none is given. Blank lines and
+// # comments are skipped. Each line is reported on its own; the exit code
+// is non-zero when any of them failed.
+func runStdin(opts options, stdin io.Reader, stdout, stderr io.Writer) int {
+	progress := func(format string, args ...interface{}) {
+		if !opts.quiet && !opts.json {
+			_, _ = fmt.Fprintf(stderr, format+"\n", args...)
+		}
+	}
+
+	failed, total := 0, 0
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		// After an interrupt the remaining charts are not attempted
+		if appContext.Err() != nil {
+			_, _ = fmt.Fprintln(stderr, "Interrupted")
+			return 1
+		}
+
+		ref := strings.TrimSpace(scanner.Text())
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			continue
+		}
+		total++
+
+		result := stdinResult{Ref: ref}
+		var err error
+		result.downloadResult, err = downloadRef(opts, ref, progress)
+		if err != nil {
+			failed++
+			result.Error = err.Error()
+			sessionLog.log(logEntry{Event: "error", Error: fmt.Sprintf("%s: %v", ref, err)})
+		}
+
+		switch {
+		case opts.json:
+			_ = json.NewEncoder(stdout).Encode(result)
+		case err != nil:
+			_, _ = fmt.Fprintf(stderr, "✗ %s: %v\n", ref, err)
+		case opts.quiet:
+			_, _ = fmt.Fprintln(stdout, result.Path)
+		default:
+			_, _ = fmt.Fprintf(stdout, "✓ %s %s → %s\n", result.Chart, result.Version, result.Path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: failed to read stdin: %v\n", err)
+		return 1
+	}
+
+	if !opts.quiet && !opts.json {
+		_, _ = fmt.Fprintf(stderr, "%d of %d charts downloaded\n", total-failed, total)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// downloadRef downloads the values of one chart reference from stdin
+func downloadRef(opts options, ref string, progress func(format string, args ...interface{})) (downloadResult, error) {
+	opts.chart, opts.version = parseChartRef(ref)
+	if opts.chart == "" {
+		return downloadResult{}, fmt.Errorf("missing chart name")
+	}
+	return resolveAndDownload(opts, progress)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// subchartCompleteMsg reports the values file written for a subchart
+type subchartCompleteMsg struct {
+	version  HelmVersion
+	subchart string
+	path     string
+}
+
+// downloadSubchartValues pulls the parent chart and writes the default values
+// of one of its bundled subcharts
+func downloadSubchartValues(repo HelmRepo, chart HelmVersion, subchart string, opts options) tea.Cmd {
+	return func() tea.Msg {
+		archive, err := pullArchive(chart.Name, chart.Version)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to pull chart: %v", err))
+		}
+
+		subcharts, err := readSubchartValues(archive)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to read chart archive: %v", err))
+		}
+
+		values, ok := subcharts[subchart]
+		if !ok {
+			return errorMsg(missingSubchart(chart, subchart, subcharts))
+		}
+
+		if err := checkValues(values, opts); err != nil {
+			return errorMsg(fmt.Sprintf("Refusing to write subchart %s: %v", subchart, err))
+		}
+		values, err = transformValues(values, opts)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to process chart values: %v", err))
+		}
+
+		values = withSourceHeader(values, fmt.Sprintf("subchart %s of %s %s", subchart, chart.Name, chart.Version), repo, opts)
+
+		chartParts := strings.Split(chart.Name, "/")
+		filename := filepath.Join(downloadDir(repo, opts), valuesFilename(opts, chartParts[len(chartParts)-1], chart.Version, subchart, "subchart-values"))
+		if err := writeValuesFile(filename, values, opts); err != nil {
+			return errorMsg(fmt.Sprintf("Failed to write values file: %v", err))
+		}
+
+		return subchartCompleteMsg{version: chart, subchart: subchart, path: filename}
+	}
+}
+
+// pullArchive pulls a chart version into a temporary directory and returns
+// the contents of the .tgz helm wrote
+func pullArchive(chartName, version string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "helm-browser-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := runHelm(appContext, "pull", "--version", version, "--destination", dir, "--", chartName); err != nil {
+		return nil, err
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil || len(archives) == 0 {
+		return nil, errors.New("no archive was written")
+	}
+	return os.ReadFile(archives[0])
+}
+
+// missingSubchart explains that a chart does not bundle the requested subchart
+func missingSubchart(chart HelmVersion, subchart string, subcharts map[string][]byte) string {
+	if len(subcharts) == 0 {
+		return fmt.Sprintf("%s %s has no subcharts", chart.Name, chart.Version)
+	}
+
+	names := make([]string, 0, len(subcharts))
+	for name := range subcharts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%s %s has no subchart %q (available: %s)", chart.Name, chart.Version, subchart, strings.Join(names, ", "))
+}
+
+// readSubchartValues returns the values.yaml of every subchart in a chart
+// archive, by subchart name. Subcharts are either directories or nested
+// archives under the parent's charts/ directory.
+func readSubchartValues(archive []byte) (map[string][]byte, error) {
+	subcharts := map[string][]byte{}
+	err := walkArchive(archive, func(name string, data []byte) error {
+		// name is <parent>/charts/<entry>...
+		parts := strings.Split(name, "/")
+		if len(parts) < 3 || parts[1] != "charts" {
+			return nil
+		}
+
+		switch {
+		case len(parts) == 4 && parts[3] == "values.yaml":
+			subcharts[parts[2]] = data
+		case len(parts) == 3 && strings.HasSuffix(parts[2], ".tgz"):
+			return walkArchive(data, func(name string, data []byte) error {
+				nested := strings.Split(name, "/")
+				if len(nested) == 2 && nested[1] == "values.yaml" {
+					subcharts[nested[0]] = data
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return subcharts, err
+}
+
+// walkArchive calls fn with the name and contents of each file in a .tgz
+func walkArchive(archive []byte, fn func(name string, data []byte) error) error {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := fn(strings.TrimPrefix(header.Name, "./"), data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sessionStats counts what was browsed during a session for the exit summary
+type sessionStats struct {
+	started time.Time
+	repos   map[string]bool
+	charts  map[string]bool
+}
+
+// viewRepo records that a repository's chart list was opened
+func (s *sessionStats) viewRepo(name string) {
+	if s.repos == nil {
+		s.repos = make(map[string]bool)
+	}
+	s.repos[name] = true
+}
+
+// viewChart records that a chart's version list was opened
+func (s *sessionStats) viewChart(name string) {
+	if s.charts == nil {
+		s.charts = make(map[string]bool)
+	}
+	s.charts[name] = true
+}
+
+// summary recaps the session for the terminal scrollback: how long it took,
+// what was browsed and the files written
+func (m model) summary(now time.Time) string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("⏱️  Session %s • %s browsed • %s viewed • %s downloaded\n",
+		now.Sub(m.stats.started).Round(time.Second),
+		plural(len(m.stats.repos), "repository", "repositories"),
+		plural(len(m.stats.charts), "chart", "charts"),
+		plural(len(m.history), "file", "files")))
+	for _, step := range m.history {
+		s.WriteString("   " + step.path + "\n")
+	}
+	return s.String()
+}
+
+// plural formats a count with the singular or plural noun
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewTab is a chart version's values kept open in a tab of the preview
+type previewTab struct {
+	version HelmVersion
+	view    viewport
+}
+
+// tabLoadedMsg carries the values of a chart version opened in a new tab
+type tabLoadedMsg struct {
+	version HelmVersion
+	values  []byte
+}
+
+// loadTab fetches the values of a chart version for a new tab
+func loadTab(version HelmVersion) tea.Cmd {
+	return func() tea.Msg {
+		values, err := fetchValues(version.Name, version.Version)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to get chart values: %v", err))
+		}
+		return tabLoadedMsg{version: version, values: values}
+	}
+}
+
+// tabIndex returns the tab holding a chart version, or -1
+func (m model) tabIndex(version HelmVersion) int {
+	for i, tab := range m.tabs {
+		if tab.version.Name == version.Name && tab.version.Version == version.Version {
+			return i
+		}
+	}
+	return -1
+}
+
+// openTab shows the highlighted version's values in the tabbed preview,
+// opening a new tab unless the version already has one
+func (m model) openTab() (tea.Model, tea.Cmd) {
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+	m.selectedVersion = m.cursor
+	version := m.versions[m.selectedVersion]
+
+	m = m.push()
+	m.state = statePreview
+	m.showingTabs = true
+	if i := m.tabIndex(version); i >= 0 {
+		m.activeTab = i
+		m.preview = m.tabs[i].view
+		return m, nil
+	}
+	m.loading = true
+	return m, loadTab(version)
+}
+
+// updateTabLoaded adds the loaded values as a new tab and shows it
+func (m model) updateTabLoaded(msg tabLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	view := newViewport(msg.values, m.viewportHeight())
+	view.title = fmt.Sprintf("👀 Values of %s %s:", msg.version.Name, msg.version.Version)
+	if m.opts.colors() {
+		view.highlight = highlightYAML
+		view.styled = make(map[int]string)
+	}
+
+	// Copy on append so earlier model values keep their own tabs
+	m.tabs = append(m.tabs[:len(m.tabs):len(m.tabs)], previewTab{version: msg.version, view: view})
+	m.activeTab = len(m.tabs) - 1
+	m.preview = view
+	return m, nil
+}
+
+// keepTab stores the preview's scroll position and search in the active tab
+func (m model) keepTab() model {
+	if m.showingTabs && m.activeTab < len(m.tabs) {
+		m.tabs = append([]previewTab(nil), m.tabs...)
+		m.tabs[m.activeTab].view = m.preview
+	}
+	return m
+}
+
+// switchTab moves delta tabs to the right, wrapping around
+func (m model) switchTab(delta int) model {
+	if len(m.tabs) < 2 {
+		return m
+	}
+	m = m.keepTab()
+	m.activeTab = (m.activeTab + delta + len(m.tabs)) % len(m.tabs)
+	m.preview = m.tabs[m.activeTab].view
+	return m
+}
+
+// closeTab closes the active tab, going back to the version list when it
+// was the last one
+func (m model) closeTab() model {
+	m.tabs = append(m.tabs[:m.activeTab:m.activeTab], m.tabs[m.activeTab+1:]...)
+	if len(m.tabs) == 0 {
+		m.activeTab = 0
+		m, _ = m.back()
+		return m
+	}
+	m.activeTab = min(m.activeTab, len(m.tabs)-1)
+	m.preview = m.tabs[m.activeTab].view
+	return m
+}
+
+// resizeTabs fits every tab's viewport to the terminal height
+func (m model) resizeTabs() model {
+	m.tabs = append([]previewTab(nil), m.tabs...)
+	for i := range m.tabs {
+		m.tabs[i].view.height = m.viewportHeight()
+		m.tabs[i].view.scroll(0)
+	}
+	return m
+}
+
+// renderTabBar draws the names of the open tabs, marking the active one
+func (m model) renderTabBar() string {
+	names := make([]string, len(m.tabs))
+	for i, tab := range m.tabs {
+		chartParts := strings.Split(tab.version.Name, "/")
+		name := fmt.Sprintf(" %d %s %s ", i+1, chartParts[len(chartParts)-1], tab.version.Version)
+		if i == m.activeTab {
+			names[i] = selectedStyle.Render("[" + name + "]")
+		} else {
+			names[i] = appVersionStyle.Render(" " + name + " ")
+		}
+	}
+	return strings.Join(names, "│")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// timelineRows is the most app version ranges shown at once
+const timelineRows = 8
+
+// appVersionRange is a run of consecutive chart versions bundling the same
+// app version. Versions are listed newest first, so oldest is the chart
+// version that introduced the app version.
+type appVersionRange struct {
+	appVersion string
+	newest     string
+	oldest     string
+	count      int
+	start, end int // indexes into the version list, end exclusive
+}
+
+// appVersionTimeline groups consecutive versions by app version
+func appVersionTimeline(versions []HelmVersion) []appVersionRange {
+	var ranges []appVersionRange
+	for i, v := range versions {
+		if n := len(ranges); n > 0 && ranges[n-1].appVersion == v.AppVersion {
+			ranges[n-1].oldest = v.Version
+			ranges[n-1].count++
+			ranges[n-1].end = i + 1
+			continue
+		}
+		ranges = append(ranges, appVersionRange{
+			appVersion: v.AppVersion,
+			newest:     v.Version,
+			oldest:     v.Version,
+			count:      1,
+			start:      i,
+			end:        i + 1,
+		})
+	}
+	return ranges
+}
+
+// renderTimeline draws the app version timeline below the version list,
+// scrolled to keep the range of the highlighted version in view
+func (m model) renderTimeline() string {
+	ranges := appVersionTimeline(m.versions)
+
+	current := 0
+	for i, r := range ranges {
+		if m.cursor >= r.start && m.cursor < r.end {
+			current = i
+			break
+		}
+	}
+	first := current - timelineRows/2
+	if first > len(ranges)-timelineRows {
+		first = len(ranges) - timelineRows
+	}
+	if first < 0 {
+		first = 0
+	}
+	last := first + timelineRows
+	if last > len(ranges) {
+		last = len(ranges)
+	}
+
+	var s strings.Builder
+	s.WriteString(selectedStyle.Render(fmt.Sprintf("🕒 App version timeline of %d chart versions, newest first", len(m.versions))) + "\n")
+	if first > 0 {
+		s.WriteString(fmt.Sprintf("   ↑ %d newer\n", first))
+	}
+	for i := first; i < last; i++ {
+		r := ranges[i]
+
+		appVer := r.appVersion
+		if appVer == "" {
+			appVer = "─"
+		}
+		span := r.oldest
+		if r.count > 1 {
+			span = fmt.Sprintf("%s → %s (%d)", r.oldest, r.newest, r.count)
+		}
+		line := fmt.Sprintf("%s %s %s", appVersionStyle.Render(fmt.Sprintf("%-15s", appVer)), chartVersionStyle.Render(fmt.Sprintf("%-25s", span)), fmt.Sprintf("first in %s", r.oldest))
+
+		if i == current {
+			s.WriteString(selectedStyle.Render(" ► ") + line + "\n")
+		} else {
+			s.WriteString("   " + line + "\n")
+		}
+	}
+	if last < len(ranges) {
+		s.WriteString(fmt.Sprintf("   ↓ %d older\n", len(ranges)-last))
+	}
+	return s.String()
+}
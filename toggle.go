@@ -0,0 +1,62 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// versionPeek keeps a version list left with V, so V on the same chart
+// shows it again as it was instead of loading it anew
+type versionPeek struct {
+	chart         string
+	devel         bool
+	allVersions   []HelmVersion
+	versions      []HelmVersion
+	minAppVersion string
+	cursor        int
+}
+
+// toggleVersions flips between the chart list and the highlighted chart's
+// version list. Unlike Enter and Esc it keeps the version list and both
+// cursors, so a chart's versions can be glanced at and left again.
+func (m model) toggleVersions() (tea.Model, tea.Cmd) {
+	switch m.state {
+	case stateVersionList:
+		if m.selectedChart >= len(m.charts) || len(m.navStack) == 0 {
+			return m, nil
+		}
+		m.peek = versionPeek{
+			chart:         m.charts[m.selectedChart].Name,
+			devel:         m.devel,
+			allVersions:   m.allVersions,
+			versions:      m.versions,
+			minAppVersion: m.minAppVersion,
+			cursor:        m.cursor,
+		}
+		// The chart list is the screen the version list was opened from
+		chart := m.selectedChart
+		m, _ = m.back()
+		m.cursor, m.selectedChart = chart, chart
+		m.rows = make(rowCache)
+		return m, nil
+
+	case stateChartList:
+		if m.cursor >= len(m.charts) {
+			return m, nil
+		}
+		chart := m.charts[m.cursor]
+		if m.peek.chart != chart.Name || m.peek.devel != m.devel {
+			m, cmd := m.openChart(m.cursor)
+			return m, cmd
+		}
+
+		m = m.push()
+		m.selectedChart = m.cursor
+		m.allVersions = m.peek.allVersions
+		m.versions = m.peek.versions
+		m.latest = latestVersion(m.allVersions)
+		m.minAppVersion = m.peek.minAppVersion
+		m.cursor = m.peek.cursor
+		m.state = stateVersionList
+		m.rows = make(rowCache)
+		return m, nil
+	}
+	return m, nil
+}
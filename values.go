@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Output formats for downloaded values
+const (
+	formatYAML = "yaml"
+	formatFlat = "flat"
+	formatJSON = "json"
+)
+
+// helm show variants for downloads: just the values, or everything the
+// chart contains (Chart.yaml, values, README and CRDs) as helm show all prints it
+const (
+	showValues = "values"
+	showAll    = "all"
+)
+
+// formatExtensions replace .yaml in the default file name for other formats
+var formatExtensions = map[string]string{
+	formatFlat: ".txt",
+	formatJSON: ".json",
+}
+
+// missingChartErrors are what helm prints when the chart or version does
+// not exist, which pulling it would fail with as well
+var missingChartErrors = []string{"not found", "no chart version", "no chart name", "404"}
+
+// fetchValues returns the default values of a chart version. When helm show
+// values fails for the reference, as it does for some OCI registries and
+// repositories, the chart is pulled and the values.yaml read from the archive.
+func fetchValues(chartName, version string) ([]byte, error) {
+	values, err := runHelm(appContext, "show", "values", "--version", version, "--", chartName)
+	if err == nil || !canPullInstead(err) {
+		return values, err
+	}
+
+	archive, pullErr := pullArchive(chartName, version)
+	if pullErr == nil {
+		values, pullErr = archiveValues(archive)
+	}
+	if pullErr != nil {
+		return nil, fmt.Errorf("could not retrieve the values of %s %s: %w; pulling the chart to read its values.yaml failed too: %v", chartName, version, err, pullErr)
+	}
+	return values, nil
+}
+
+// canPullInstead reports whether values helm show values could not print may
+// still be read by pulling the chart: helm ran and failed, but not because
+// the chart is missing or the command was stopped
+func canPullInstead(err error) bool {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	stderr := strings.ToLower(cmdErr.stderr)
+	for _, missing := range missingChartErrors {
+		if strings.Contains(stderr, missing) {
+			return false
+		}
+	}
+	return true
+}
+
+// archiveValues returns the values.yaml at the top of a chart archive; a
+// chart without one has no default values
+func archiveValues(archive []byte) ([]byte, error) {
+	values := []byte{}
+	err := walkArchive(archive, func(name string, data []byte) error {
+		if parts := strings.Split(name, "/"); len(parts) == 2 && parts[1] == "values.yaml" {
+			values = data
+		}
+		return nil
+	})
+	return values, err
+}
+
+// withSourceHeader prepends the --source-header comment recording where the
+// values came from. Being a comment, it leaves YAML parsing unaffected.
+func withSourceHeader(values []byte, source string, repo HelmRepo, opts options) []byte {
+	if !opts.sourceHeader {
+		return values
+	}
+	header := fmt.Sprintf("# Downloaded from %s on %s\n", source, time.Now().UTC().Format(time.RFC3339))
+	if repo.URL != "" && repo.URL != repo.Name {
+		header += fmt.Sprintf("# Repository: %s (%s)\n", repo.Name, repo.URL)
+	}
+	return append([]byte(header), values...)
+}
+
+// checkValues makes sure values parse as YAML before they are written with
+// --validate. The error names the line where they break.
+func checkValues(values []byte, opts options) error {
+	if !opts.validate {
+		return nil
+	}
+	if _, err := parseYAML(values); err != nil {
+		return fmt.Errorf("values are not valid YAML (--validate): %w", err)
+	}
+	return nil
+}
+
+// transformValues applies the output options to the raw helm show values output
+// before it is written. With no options set the values are returned unchanged;
+// re-emitted output is indented by opts.indent spaces per level.
+func transformValues(values []byte, opts options) ([]byte, error) {
+	if !opts.stripComments && !opts.minify && opts.format == formatYAML {
+		return values, nil
+	}
+
+	doc, err := parseYAML(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse values: %w", err)
+	}
+	if opts.minify {
+		minifyValues(doc)
+	}
+
+	switch opts.format {
+	case formatFlat:
+		return flattenValues(doc), nil
+	case formatJSON:
+		return jsonValues(doc, opts.indent)
+	}
+	return marshalYAML(doc, opts.indent), nil
+}
+
+// minifyValues removes the mapping keys whose values are null or empty
+// mappings and sequences, then the mappings left empty by that, so only the
+// defaults that are actually set remain. Sequence items are kept to preserve
+// positions. It reports whether n itself ended up empty.
+func minifyValues(n *yamlNode) bool {
+	switch n.kind {
+	case yamlMap:
+		keys, items := n.keys[:0], n.items[:0]
+		for i, item := range n.items {
+			if !minifyValues(item) {
+				keys, items = append(keys, n.keys[i]), append(items, item)
+			}
+		}
+		n.keys, n.items = keys, items
+	case yamlSeq:
+		for _, item := range n.items {
+			minifyValues(item)
+		}
+	default:
+		return n.isNull()
+	}
+	return len(n.items) == 0
+}
+
+// jsonValues converts a values document to JSON. Mappings keep their
+// document order and scalars keep their YAML types (numbers, booleans, null).
+func jsonValues(doc *yamlNode, indent int) ([]byte, error) {
+	// Charts without default values still get a valid values object
+	if doc.kind == yamlScalar && doc.isNull() {
+		return []byte("{}\n"), nil
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", strings.Repeat(" ", indent)); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// writeJSON writes the compact JSON encoding of a node
+func writeJSON(buf *bytes.Buffer, n *yamlNode) error {
+	switch n.kind {
+	case yamlMap:
+		buf.WriteString("{")
+		for i, key := range n.keys {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			k, _ := json.Marshal(key)
+			buf.Write(k)
+			buf.WriteString(":")
+			if err := writeJSON(buf, n.items[i]); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		buf.WriteString("}")
+	case yamlSeq:
+		buf.WriteString("[")
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		buf.WriteString("]")
+	default:
+		// Infinity and NaN have no JSON form, so they fail here
+		value, err := json.Marshal(n.toValue())
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+	}
+	return nil
+}
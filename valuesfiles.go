@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// valuesFile is a values file bundled in a chart archive, such as
+// values-production.yaml next to the default values.yaml
+type valuesFile struct {
+	name string
+	data []byte
+}
+
+// valuesFilesMsg carries the values files found in a pulled chart
+type valuesFilesMsg []valuesFile
+
+// valuesFileWrittenMsg reports a bundled values file written to disk
+type valuesFileWrittenMsg struct {
+	version HelmVersion
+	name    string
+	path    string
+}
+
+// isValuesFile reports whether a path inside a chart is a values file: a
+// values*.yaml at the top of the chart or a YAML file in its values/ directory
+func isValuesFile(name string) bool {
+	ext := path.Ext(name)
+	if ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	dir, file := path.Split(name)
+	return dir == "" && strings.HasPrefix(file, "values") || dir == "values/"
+}
+
+// bundledValuesFiles returns the values files of a chart archive, the
+// default values.yaml first and the others by name. Subcharts are skipped.
+func bundledValuesFiles(archive []byte) ([]valuesFile, error) {
+	var files []valuesFile
+	err := walkArchive(archive, func(name string, data []byte) error {
+		// name is <chart>/<path inside the chart>
+		if _, inChart, ok := strings.Cut(name, "/"); ok && isValuesFile(inChart) {
+			files = append(files, valuesFile{name: inChart, data: data})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool {
+		if (files[i].name == "values.yaml") != (files[j].name == "values.yaml") {
+			return files[i].name == "values.yaml"
+		}
+		return files[i].name < files[j].name
+	})
+	return files, err
+}
+
+// loadValuesFiles pulls a chart version and lists its bundled values files
+func loadValuesFiles(version HelmVersion) tea.Cmd {
+	return func() tea.Msg {
+		archive, err := pullArchive(version.Name, version.Version)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to pull chart: %v", err))
+		}
+		files, err := bundledValuesFiles(archive)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to read chart archive: %v", err))
+		}
+		return valuesFilesMsg(files)
+	}
+}
+
+// bundledValuesPath returns where a bundled values file is written, e.g.
+// redis-19.0.1-values-production.yaml. A .yml file keeps its extension
+// unless another format or --extension asks for a different one.
+func bundledValuesPath(repo HelmRepo, chart HelmVersion, name string, opts options) string {
+	chartParts := strings.Split(chart.Name, "/")
+	base := strings.Join([]string{chartParts[len(chartParts)-1], chart.Version, strings.ReplaceAll(name, "/", "-")}, "-")
+	return filepath.Join(downloadDir(repo, opts), withValuesExtension(base, opts))
+}
+
+// writeBundledValues applies the output options to a bundled values file
+// and writes it
+func writeBundledValues(repo HelmRepo, chart HelmVersion, file valuesFile, opts options) tea.Cmd {
+	return func() tea.Msg {
+		if err := checkValues(file.data, opts); err != nil {
+			return errorMsg(fmt.Sprintf("Refusing to write %s: %v", file.name, err))
+		}
+		values, err := transformValues(file.data, opts)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("Failed to process chart values: %v", err))
+		}
+		values = withSourceHeader(values, fmt.Sprintf("%s of %s %s", file.name, chart.Name, chart.Version), repo, opts)
+
+		filename := bundledValuesPath(repo, chart, file.name, opts)
+		if err := writeValuesFile(filename, values, opts); err != nil {
+			return errorMsg(fmt.Sprintf("Failed to write values file: %v", err))
+		}
+		return valuesFileWrittenMsg{version: chart, name: file.name, path: filename}
+	}
+}
+
+// openValuesFiles pulls the highlighted version to list its values files
+func (m model) openValuesFiles() (tea.Model, tea.Cmd) {
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+	m.selectedVersion = m.cursor
+	m = m.push()
+	m.valuesFiles = nil
+	m.cursor = 0
+	m.loading = true
+	m.state = stateValuesFiles
+	return m, loadValuesFiles(m.versions[m.selectedVersion])
+}
+
+// downloadValuesFile writes the bundled values file at index
+func (m model) downloadValuesFile(index int) (tea.Model, tea.Cmd) {
+	version := m.versions[m.selectedVersion]
+	file := m.valuesFiles[index]
+	m.cursor = index
+	m = m.push()
+	m.loading = true
+	m.activity = fmt.Sprintf("⬇️  Writing %s...", file.name)
+	m.state = stateDownload
+	return m, writeBundledValues(m.repoFor(version.Name), version, file, m.opts)
+}
+
+// previewValuesFile shows the highlighted bundled values file
+func (m model) previewValuesFile() (tea.Model, tea.Cmd) {
+	if m.cursor >= len(m.valuesFiles) {
+		return m, nil
+	}
+	version := m.versions[m.selectedVersion]
+	file := m.valuesFiles[m.cursor]
+	m = m.push()
+	m.state = statePreview
+	m.preview = newViewport(file.data, m.viewportHeight())
+	m.preview.title = fmt.Sprintf("👀 %s of %s %s:", file.name, version.Name, version.Version)
+	if m.opts.colors() {
+		m.preview.highlight = highlightYAML
+		m.preview.styled = make(map[int]string)
+	}
+	return m, nil
+}
+
+// renderValuesFiles draws the values files bundled with the chart
+func (m model) renderValuesFiles() string {
+	version := m.versions[m.selectedVersion]
+
+	var s strings.Builder
+	if m.loading {
+		s.WriteString(fmt.Sprintf("📦 Pulling %s %s to find its values files...\n", version.Name, version.Version))
+		return s.String()
+	}
+	if len(m.valuesFiles) == 0 {
+		s.WriteString(fmt.Sprintf("📭 %s %s bundles no values files.\n", version.Name, version.Version))
+		return s.String()
+	}
+
+	s.WriteString(fmt.Sprintf("📄 Values files bundled with %s %s:\n\n", version.Name, version.Version))
+	s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-40s %s\n", "", "FILE", "LINES"), len(m.valuesFiles)))
+	s.WriteString(m.gutterBlock(fmt.Sprintf("%-4s %-40s %s\n", "────", strings.Repeat("─", 40), "─────"), len(m.valuesFiles)))
+
+	start := m.getPageStart()
+	end := m.getPageEnd(len(m.valuesFiles))
+	for i := start; i < end; i++ {
+		file := m.valuesFiles[i]
+		lines := fmt.Sprint(bytes.Count(file.data, []byte("\n")))
+		if file.name == "values.yaml" {
+			lines += " (the default, as helm show values prints it)"
+		}
+
+		line := fmt.Sprintf("%-4s %s %s", m.rowLabel(i),
+			chartVersionStyle.Render(fmt.Sprintf("%-40s", shorten(file.name, 40))),
+			appVersionStyle.Render(lines))
+		s.WriteString(m.gutter(i, len(m.valuesFiles)))
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("► "+line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	info := fmt.Sprintf("📄 %d values files", len(m.valuesFiles))
+	if rows := m.scrollInfo(len(m.valuesFiles), "values files"); rows != "" {
+		info = rows
+	} else if totalPages := m.getTotalPages(); totalPages > 1 {
+		info = fmt.Sprintf("📄 Page %d of %d • %d values files", m.getCurrentPage()+1, totalPages, len(m.valuesFiles))
+	}
+	s.WriteString(helpStyle.Render(info))
+	return s.String()
+}
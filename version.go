@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// isPrerelease reports whether a semantic version has a prerelease part,
+// e.g. 1.2.0-rc.1, which helm only lists with --devel
+func isPrerelease(version string) bool {
+	core, _, _ := strings.Cut(version, "+")
+	return strings.Contains(core, "-")
+}
+
+// semver is a parsed semantic version; build metadata is ignored
+type semver struct {
+	core  [3]int
+	pre   []string
+	valid bool
+}
+
+// parseSemver parses versions such as 1.2.3, v1.2 or 1.2.3-rc.1+build.
+// Missing minor and patch numbers count as zero.
+func parseSemver(version string) semver {
+	version, _, _ = strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, pre, hasPre := strings.Cut(version, "-")
+
+	var v semver
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+	}
+	v.valid = true
+	return v
+}
+
+// compareVersions orders two chart versions by semantic version precedence,
+// returning -1, 0 or 1. Versions that do not parse sort before those that do.
+func compareVersions(a, b string) int {
+	va, vb := parseSemver(a), parseSemver(b)
+	switch {
+	case !va.valid && !vb.valid:
+		return strings.Compare(a, b)
+	case !va.valid:
+		return -1
+	case !vb.valid:
+		return 1
+	}
+
+	for i := range va.core {
+		if va.core[i] != vb.core[i] {
+			return cmpInt(va.core[i], vb.core[i])
+		}
+	}
+
+	// A prerelease comes before the release itself
+	switch {
+	case len(va.pre) == 0 && len(vb.pre) == 0:
+		return 0
+	case len(va.pre) == 0:
+		return 1
+	case len(vb.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(va.pre) && i < len(vb.pre); i++ {
+		if c := comparePrerelease(va.pre[i], vb.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return cmpInt(len(va.pre), len(vb.pre))
+}
+
+// comparePrerelease compares one dot-separated prerelease identifier:
+// numbers numerically and before words, words lexically
+func comparePrerelease(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return cmpInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// cmpInt returns -1, 0 or 1 as a is less than, equal to or greater than b
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// latestVersion returns the highest stable version in the list, or the
+// highest version when every one is a prerelease. The list order is not
+// relied on, so the result stays right whatever the list is sorted by.
+func latestVersion(versions []HelmVersion) string {
+	latest, latestStable := "", ""
+	for _, v := range versions {
+		if latest == "" || compareVersions(v.Version, latest) > 0 {
+			latest = v.Version
+		}
+		if !isPrerelease(v.Version) && (latestStable == "" || compareVersions(v.Version, latestStable) > 0) {
+			latestStable = v.Version
+		}
+	}
+	if latestStable != "" {
+		return latestStable
+	}
+	return latest
+}
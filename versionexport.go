@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportedVersion is a row of the version list export
+type exportedVersion struct {
+	Version    string `json:"version"`
+	AppVersion string `json:"app_version"`
+	Created    string `json:"created"`
+}
+
+// versionListMsg reports the version list written by the export
+type versionListMsg struct {
+	count int
+	path  string
+	err   error
+}
+
+// Formats of the version list export, chosen with --export-format
+const (
+	exportCSV  = "csv"
+	exportJSON = "json"
+)
+
+// indexCreated returns when each version of a chart was created, read from
+// the index helm repo update caches for the repository. helm search does
+// not report it, so the dates are empty when the cached index is missing,
+// e.g. for OCI registries.
+func indexCreated(env helmEnv, repo, chart string) map[string]string {
+	path := env.repositoryIndex(repo)
+	if path == "" {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	return scanIndexCreated(file, chart)
+}
+
+// scanIndexCreated reads the created date of each version of a chart from a
+// repository index as helm writes it:
+//
+//	entries:
+//	  redis:
+//	  - appVersion: 7.2.4
+//	    created: "2024-03-01T10:00:00Z"
+//	    version: 19.0.1
+//
+// A line-based scan keeps large indexes cheap without a YAML parser.
+func scanIndexCreated(index io.Reader, chart string) map[string]string {
+	created := map[string]string{}
+	var inChart bool
+	var date, version string
+	flush := func() {
+		if version != "" {
+			created[version] = date
+		}
+		date, version = "", ""
+	}
+
+	scanner := bufio.NewScanner(index)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "  - "):
+			if inChart {
+				flush()
+				line = "    " + line[4:]
+			}
+		case strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   ") && strings.HasSuffix(line, ":"):
+			// The next chart's entries start
+			if inChart {
+				flush()
+				return created
+			}
+			inChart = strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `"'`) == chart
+			continue
+		case !strings.HasPrefix(line, " ") && inChart:
+			flush()
+			return created
+		}
+		if !inChart {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok && strings.HasPrefix(key, "    ") && !strings.HasPrefix(key, "     ") {
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			switch strings.TrimSpace(key) {
+			case "created":
+				date = value
+			case "version":
+				version = value
+			}
+		}
+	}
+	flush()
+	return created
+}
+
+// versionListExport renders versions as CSV or JSON, with the created
+// dates looked up in created
+func versionListExport(versions []HelmVersion, created map[string]string, opts options) ([]byte, error) {
+	rows := make([]exportedVersion, len(versions))
+	for i, version := range versions {
+		rows[i] = exportedVersion{Version: version.Version, AppVersion: version.AppVersion, Created: version.Created}
+		if rows[i].Created == "" {
+			rows[i].Created = created[version.Version]
+		}
+	}
+
+	if opts.exportFormat == exportJSON {
+		data, err := json.MarshalIndent(rows, "", strings.Repeat(" ", opts.indent))
+		return append(data, '\n'), err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"version", "app_version", "created"})
+	for _, row := range rows {
+		w.Write([]string{row.Version, row.AppVersion, row.Created})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// exportVersionList writes versions to path, or copies them to the
+// clipboard when path is empty
+func exportVersionList(env helmEnv, repo HelmRepo, versions []HelmVersion, path string, opts options) tea.Cmd {
+	return func() tea.Msg {
+		if env.RepositoryCache == "" {
+			// helm env failed at startup
+			env = helmEnvironment()
+		}
+		chartParts := strings.Split(versions[0].Name, "/")
+		data, err := versionListExport(versions, indexCreated(env, repo.Name, chartParts[len(chartParts)-1]), opts)
+		if err == nil && path == "" {
+			return clipboardMsg{text: fmt.Sprintf("%d versions", len(versions)), err: copyToClipboard(string(data))}
+		}
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		return versionListMsg{count: len(versions), path: path, err: err}
+	}
+}
+
+// openVersionListExport asks where to write the version list
+func (m model) openVersionListExport() model {
+	if len(m.versions) == 0 {
+		return m
+	}
+	chartParts := strings.Split(m.versions[0].Name, "/")
+	format := m.opts.exportFormat
+	m = m.openInput(inputVersionList, fmt.Sprintf("📊 Write %d versions as %s to (empty copies them):", len(m.versions), strings.ToUpper(format)))
+	m.input.value = fmt.Sprintf("%s-versions.%s", chartParts[len(chartParts)-1], format)
+	return m
+}
+
+// submitVersionListExport exports the version list as shown, filters and
+// sort order included. An existing file is only replaced with --force.
+func (m model) submitVersionListExport(path string) (tea.Model, tea.Cmd) {
+	if _, err := os.Stat(path); path != "" && err == nil && !m.opts.force {
+		m.input.err = fmt.Sprintf("%s already exists (use --force to replace it)", path)
+		return m, nil
+	}
+	m.input = inputPrompt{}
+	return m, exportVersionList(m.helmEnv, m.repoFor(m.versions[0].Name), m.versions, path, m.opts)
+}
+
+// updateVersionList reports the written version list
+func (m model) updateVersionList(msg versionListMsg) model {
+	if msg.err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("❌ Export failed: %v", msg.err))
+		return m
+	}
+	m.status = downloadedStyle.Render(fmt.Sprintf("📊 %d versions written to %s", msg.count, msg.path))
+	return m
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// artifactHubPackagesURL is the ArtifactHub Helm package endpoint; tests
+// point it at a local server
+var artifactHubPackagesURL = "https://artifacthub.io/api/v1/packages/helm"
+
+// hubVersionsMsg carries the versions ArtifactHub knows for a chart whose
+// repository index lists a single one
+type hubVersionsMsg struct {
+	chart    string
+	versions []string
+	err      error
+}
+
+// singleVersionSuspicious reports whether a version list looks cut short:
+// it holds a single version that is not a chart's first release, which
+// usually means the repository index only keeps the latest one or is out of
+// date. OCI registries list their tags, so they are trusted.
+func singleVersionSuspicious(versions []HelmVersion) bool {
+	if len(versions) != 1 || isOCIRef(versions[0].Name) {
+		return false
+	}
+	v := parseSemver(versions[0].Version)
+	first := v.core[0] == 0 && v.core[1] <= 1 || v.core == [3]int{1, 0, 0}
+	return v.valid && !first
+}
+
+// loadHubVersions asks ArtifactHub for the versions of a chart, looking it
+// up under the local repository name
+func loadHubVersions(chartName string) tea.Cmd {
+	return func() tea.Msg {
+		versions, err := queryHubVersions(chartName)
+		return hubVersionsMsg{chart: chartName, versions: versions, err: err}
+	}
+}
+
+// queryHubVersions reads the available versions of a repo/chart from the
+// ArtifactHub package API, newest first as ArtifactHub lists them
+func queryHubVersions(chartName string) ([]string, error) {
+	repo, chart, ok := strings.Cut(chartName, "/")
+	if !ok {
+		return nil, fmt.Errorf("%s is not a repo/chart name", chartName)
+	}
+	req, err := http.NewRequestWithContext(appContext, http.MethodGet, artifactHubPackagesURL+"/"+url.PathEscape(repo)+"/"+url.PathEscape(chart), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := artifactHubClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach ArtifactHub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ArtifactHub returned %s", resp.Status)
+	}
+
+	var pkg struct {
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"available_versions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse the ArtifactHub response: %w", err)
+	}
+	versions := make([]string, len(pkg.Versions))
+	for i, v := range pkg.Versions {
+		versions[i] = v.Version
+	}
+	return versions, nil
+}
+
+// singleVersionHint explains a version list that holds a single version
+// although the chart has likely had others, with what ArtifactHub lists
+// when --artifacthub is set
+func (m model) singleVersionHint() string {
+	if !singleVersionSuspicious(m.allVersions) {
+		return ""
+	}
+	version := m.allVersions[0]
+	repo, _, _ := strings.Cut(version.Name, "/")
+
+	lines := []string{
+		fmt.Sprintf("ℹ️  Only %s is listed. The local index may be out of date: run helm repo update %s, then %s to reload.", version.Version, repo, keyLabel(m.reloadKey())),
+		"   Some repositories only keep the latest release in their index, so older versions cannot be downloaded from them.",
+	}
+	switch hub := m.hubVersions; {
+	case !m.opts.artifactHub || hub.chart != version.Name:
+	case hub.err != nil:
+		lines = append(lines, fmt.Sprintf("   ArtifactHub lookup failed: %v", hub.err))
+	case len(hub.versions) > 1:
+		shown := hub.versions[:min(len(hub.versions), 5)]
+		more := ""
+		if len(hub.versions) > len(shown) {
+			more = ", …"
+		}
+		lines = append(lines, fmt.Sprintf("   ArtifactHub lists %d versions: %s%s", len(hub.versions), strings.Join(shown, ", "), more))
+	default:
+		lines = append(lines, "   ArtifactHub lists no other versions either.")
+	}
+	return helpStyle.Render(strings.Join(lines, "\n"))
+}
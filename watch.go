@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchTickMsg asks for the watched version list to be refreshed
+type watchTickMsg time.Time
+
+// watchRefreshMsg carries a refreshed version list
+type watchRefreshMsg struct {
+	chart    string
+	versions []HelmVersion
+	err      error
+}
+
+// watchTick schedules the next refresh with --watch
+func watchTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return watchTickMsg(t)
+	})
+}
+
+// refreshVersions updates the chart's repository index and searches its
+// versions again. helm only sees new releases after the index is updated;
+// OCI registries are asked directly.
+func refreshVersions(repo HelmRepo, chartName string, devel bool) tea.Cmd {
+	return func() tea.Msg {
+		if repo.Name != "" && !isOCIRef(chartName) {
+			if _, err := runHelm(appContext, "repo", "update", "--", repo.Name); err != nil {
+				return watchRefreshMsg{chart: chartName, err: err}
+			}
+		}
+
+		switch msg := loadVersions(chartName, devel)().(type) {
+		case versionsLoadedMsg:
+			return watchRefreshMsg{chart: chartName, versions: msg}
+		case errorMsg:
+			return watchRefreshMsg{chart: chartName, err: fmt.Errorf("%s", msg)}
+		default:
+			return watchRefreshMsg{chart: chartName, err: fmt.Errorf("unexpected result %T", msg)}
+		}
+	}
+}
+
+// updateWatchTick refreshes the version list on screen, or waits for the
+// next tick when there is nothing to watch right now
+func (m model) updateWatchTick() (tea.Model, tea.Cmd) {
+	if m.state != stateVersionList || m.loading || m.watching {
+		return m, watchTick(m.opts.watch)
+	}
+
+	chartName := m.charts[m.selectedChart].Name
+	m.watching = true
+	return m, refreshVersions(m.repoFor(chartName), chartName, m.devel)
+}
+
+// updateWatchRefresh marks versions that were not there before as new and
+// keeps the cursor on the same version
+func (m model) updateWatchRefresh(msg watchRefreshMsg) (tea.Model, tea.Cmd) {
+	m.watching = false
+	next := watchTick(m.opts.watch)
+
+	// A refresh landing while a version is being worked on waits for the
+	// next tick, so the list does not shift under it
+	if m.state != stateVersionList || m.loading || m.charts[m.selectedChart].Name != msg.chart {
+		return m, next
+	}
+	m.lastWatch = time.Now()
+	if msg.err != nil {
+		m.status = errorStyle.Render(fmt.Sprintf("👀 Refresh failed: %v", msg.err))
+		return m, next
+	}
+
+	known := make(map[string]bool, len(m.allVersions))
+	for _, v := range m.allVersions {
+		known[v.Version] = true
+	}
+	var added []string
+	for _, v := range msg.versions {
+		if !known[v.Version] {
+			added = append(added, v.Version)
+			m.newVersions[downloadKey(v.Name, v.Version)] = true
+		}
+	}
+	if len(added) == 0 {
+		return m, next
+	}
+
+	current, selected := "", ""
+	if m.cursor < len(m.versions) {
+		current = m.versions[m.cursor].Version
+	}
+	if m.selectedVersion < len(m.versions) {
+		selected = m.versions[m.selectedVersion].Version
+	}
+	m.allVersions = msg.versions
+	m.latest = latestVersion(msg.versions)
+	m = m.applyAppVersionFilter()
+	for i, v := range m.versions {
+		if v.Version == current {
+			m.cursor = i
+		}
+		if v.Version == selected {
+			m.selectedVersion = i
+		}
+	}
+
+	m.status = latestBadgeStyle.Render(fmt.Sprintf("🆕 %s: %s", plural(len(added), "new version", "new versions"), strings.Join(added, ", ")))
+	return m, next
+}
+
+// watchNote describes the watch schedule under the version list
+func (m model) watchNote() string {
+	note := fmt.Sprintf("👀 Watching for new versions every %s", m.opts.watch)
+	if !m.lastWatch.IsZero() {
+		note += " • last checked " + m.lastWatch.Format("15:04:05")
+	}
+	return note
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values are parsed with gopkg.in/yaml.v3 into a small tree that keeps the
+// order of mapping keys and how scalars were written, so they can be
+// re-emitted faithfully. Comments are dropped, which is what --strip-comments
+// relies on. Scalars are typed by the YAML 1.1 rules helm reads values with,
+// so yes/on are booleans and 0755 is an octal number.
+
+// yamlFloat matches the float formats of YAML 1.1
+var yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+// yamlKind identifies the kind of a parsed YAML node
+type yamlKind int
+
+// YAML node kinds
+const (
+	yamlScalar yamlKind = iota
+	yamlMap
+	yamlSeq
+)
+
+// scalarStyle records how a scalar was written so it can be re-emitted faithfully
+type scalarStyle int
+
+// Scalar styles
+const (
+	stylePlain scalarStyle = iota
+	styleSingle
+	styleDouble
+	styleLiteral
+)
+
+// yamlNode is a parsed YAML value. Mappings keep their keys in document order.
+// line and col locate the value in the source, counting from 1.
+type yamlNode struct {
+	kind  yamlKind
+	value string
+	style scalarStyle
+	keys  []string
+	items []*yamlNode
+	line  int
+	col   int
+}
+
+// parseYAML parses the first YAML document. An empty document yields a null scalar.
+func parseYAML(data []byte) (*yamlNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &yamlNode{kind: yamlScalar, value: "null"}, nil
+	}
+	return convertYAML(doc.Content[0], map[*yaml.Node]*yamlNode{}), nil
+}
+
+// convertYAML builds the tree for a parsed node. Aliases share the node of
+// their anchor, which seen remembers.
+func convertYAML(n *yaml.Node, seen map[*yaml.Node]*yamlNode) *yamlNode {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if node, ok := seen[n]; ok {
+		return node
+	}
+
+	node := &yamlNode{line: n.Line, col: n.Column}
+	seen[n] = node
+	switch n.Kind {
+	case yaml.MappingNode:
+		node.kind = yamlMap
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			node.keys = append(node.keys, n.Content[i].Value)
+			node.items = append(node.items, convertYAML(n.Content[i+1], seen))
+		}
+	case yaml.SequenceNode:
+		node.kind = yamlSeq
+		for _, item := range n.Content {
+			node.items = append(node.items, convertYAML(item, seen))
+		}
+	default:
+		node.value = n.Value
+		switch {
+		case n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+			node.style = styleLiteral
+		case n.Style&yaml.SingleQuotedStyle != 0:
+			node.style = styleSingle
+		case n.Style&yaml.DoubleQuotedStyle != 0:
+			node.style = styleDouble
+		case n.Style&yaml.TaggedStyle != 0 && n.ShortTag() == "!!str":
+			// An explicit string tag turns a plain scalar into a quoted one
+			node.style = styleDouble
+		}
+	}
+	return node
+}
+
+// get returns the value stored under key in a mapping node, or nil
+func (n *yamlNode) get(key string) *yamlNode {
+	if n == nil || n.kind != yamlMap {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k == key {
+			return n.items[i]
+		}
+	}
+	return nil
+}
+
+// isNull reports whether the node is a null scalar
+func (n *yamlNode) isNull() bool {
+	if n == nil {
+		return true
+	}
+	if n.kind != yamlScalar || n.style != stylePlain {
+		return false
+	}
+	switch n.value {
+	case "", "~", "null", "Null", "NULL":
+		return true
+	}
+	return false
+}
+
+// text returns the string value of a scalar node, or "" for null and collections
+func (n *yamlNode) text() string {
+	if n == nil || n.kind != yamlScalar || n.isNull() {
+		return ""
+	}
+	return n.value
+}
+
+// toValue converts the node into plain Go values (map[string]any, []any,
+// string, bool, int64, float64 or nil) the way helm types them
+func (n *yamlNode) toValue() any {
+	if n == nil {
+		return nil
+	}
+	switch n.kind {
+	case yamlMap:
+		m := make(map[string]any, len(n.keys))
+		for i, k := range n.keys {
+			m[k] = n.items[i].toValue()
+		}
+		return m
+	case yamlSeq:
+		s := make([]any, len(n.items))
+		for i, item := range n.items {
+			s[i] = item.toValue()
+		}
+		return s
+	}
+
+	if n.style != stylePlain {
+		return n.value
+	}
+	if n.isNull() {
+		return nil
+	}
+	switch n.value {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON", "true", "True", "TRUE":
+		return true
+	case "n", "N", "no", "No", "NO", "off", "Off", "OFF", "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	// Leading zeros mean octal, and digits may be grouped with underscores
+	plain := strings.ReplaceAll(n.value, "_", "")
+	if i, err := strconv.ParseInt(plain, 0, 64); err == nil {
+		return i
+	}
+	if yamlFloat.MatchString(plain) {
+		if f, err := strconv.ParseFloat(plain, 64); err == nil {
+			return f
+		}
+	}
+	return n.value
+}
+
+// marshalYAML renders the node as block-style YAML using indent spaces per level
+func marshalYAML(n *yamlNode, indent int) []byte {
+	var b strings.Builder
+	switch {
+	case n.kind == yamlMap && len(n.keys) > 0, n.kind == yamlSeq && len(n.items) > 0:
+		writeYAMLBlock(&b, n, 0, indent)
+	default:
+		b.WriteString(scalarYAML(n, 0, indent))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// writeYAMLBlock writes a non-empty mapping or sequence at the given column
+func writeYAMLBlock(b *strings.Builder, n *yamlNode, col, indent int) {
+	pad := strings.Repeat(" ", col)
+
+	if n.kind == yamlMap {
+		for i, k := range n.keys {
+			b.WriteString(pad + formatKey(k) + ":")
+			writeYAMLChild(b, n.items[i], col, indent, col+indent)
+		}
+		return
+	}
+
+	for _, item := range n.items {
+		b.WriteString(pad + "-")
+		if (item.kind == yamlMap && len(item.keys) > 0) || (item.kind == yamlSeq && len(item.items) > 0) {
+			// Compact form: the first entry shares the dash's line
+			var inner strings.Builder
+			writeYAMLBlock(&inner, item, col+2, indent)
+			b.WriteString(" " + strings.TrimPrefix(inner.String(), strings.Repeat(" ", col+2)))
+			continue
+		}
+		writeYAMLChild(b, item, col, indent, col+2)
+	}
+}
+
+// writeYAMLChild writes the value of a mapping entry or sequence item
+func writeYAMLChild(b *strings.Builder, n *yamlNode, col, indent, childCol int) {
+	if (n.kind == yamlMap && len(n.keys) > 0) || (n.kind == yamlSeq && len(n.items) > 0) {
+		b.WriteString("\n")
+		writeYAMLBlock(b, n, childCol, indent)
+		return
+	}
+	b.WriteString(" " + scalarYAML(n, col, indent) + "\n")
+}
+
+// scalarYAML renders a scalar or empty collection for inline use
+func scalarYAML(n *yamlNode, col, indent int) string {
+	switch n.kind {
+	case yamlMap:
+		return "{}"
+	case yamlSeq:
+		return "[]"
+	}
+
+	switch n.style {
+	case stylePlain:
+		if n.value == "" {
+			return "null"
+		}
+		return n.value
+	case styleSingle:
+		if strings.Contains(n.value, "\n") {
+			return strconv.Quote(n.value)
+		}
+		return "'" + strings.ReplaceAll(n.value, "'", "''") + "'"
+	case styleLiteral:
+		return literalYAML(n.value, col, indent)
+	}
+	return doubleQuote(n.value)
+}
+
+// literalYAML renders a multi-line string as a literal block scalar whose
+// content is indented one level deeper than col
+func literalYAML(value string, col, indent int) string {
+	if value == "" || strings.TrimSpace(value) == "" && strings.Trim(value, "\n") != "" {
+		return doubleQuote(value)
+	}
+
+	header := "|"
+	body := value
+	switch {
+	case !strings.HasSuffix(value, "\n"):
+		header = "|-"
+	case strings.HasSuffix(value, "\n\n"):
+		header = "|+"
+		body = strings.TrimSuffix(value, "\n")
+	default:
+		body = strings.TrimSuffix(value, "\n")
+	}
+	if strings.HasPrefix(body, " ") {
+		header += strconv.Itoa(indent)
+	}
+
+	pad := strings.Repeat(" ", col+indent)
+	lines := strings.Split(body, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = pad + l
+		}
+	}
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+// doubleQuote renders s as a YAML double-quoted scalar
+func doubleQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r >= 0x7f && r <= 0x9f:
+			// Control characters are not printable in YAML, while every
+			// other rune, U+FFFD included, is written as it is
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case r == 0xfffe || r == 0xffff:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatKey quotes a mapping key when it could not be read back as plain
+// text, or when helm's YAML 1.1 would read it as a bool, null or number
+func formatKey(k string) string {
+	if k == "" || strings.ContainsAny(k, ":#{}[],&*!|>'\"%@`\n") || strings.TrimSpace(k) != k || strings.HasPrefix(k, "-") {
+		return doubleQuote(k)
+	}
+	if _, ok := (&yamlNode{kind: yamlScalar, value: k}).toValue().(string); !ok {
+		return doubleQuote(k)
+	}
+	return k
+}